@@ -2,14 +2,13 @@ package bdatamatrix
 
 import (
 	"bytes"
-	"encoding/csv"
-	"encoding/json"
+	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // BDataMatrix defines the behavior for a structured tabular data matrix.
@@ -139,7 +138,7 @@ type BDataMatrix interface {
 	//   - withHeader: Want return with header or not.
 	// Returns:
 	//   - If param true, return csv data include header.
-	ToCSV(withHeader bool) Output
+	ToCSV(withHeader bool) StreamingOutput
 
 	// ToTSV exports the matrix to TSV format.
 	//
@@ -147,10 +146,10 @@ type BDataMatrix interface {
 	//   - withHeader: Want return with header or not.
 	// Returns:
 	//   - If param true, return TSV data include header.
-	ToTSV(withHeader bool) Output
+	ToTSV(withHeader bool) StreamingOutput
 
 	// ToYAML exports the matrix to YAML format.
-	ToYAML() Output
+	ToYAML() StreamingOutput
 
 	// ToJSON exports the matrix to JSON format.
 	//
@@ -158,16 +157,18 @@ type BDataMatrix interface {
 	//   - compact: Want return json data with format minified (compact) or not.
 	// Returns:
 	//   - If param true, return json data with format minified (compact). If param false, return json data with format pretty-printed.
-	ToJSON(compact bool) Output
+	ToJSON(compact bool) StreamingOutput
 
-	// ToCustom exports the matrix to a custom format using a specified separator.
+	// ToCustom exports the matrix to a custom format using a specified separator. Values holding the
+	// separator, a double quote or a line break are enclosed in double quotes, with their double
+	// quotes doubled, so they cannot be mistaken for several values or rows.
 	//
 	// Parameters:
 	//   - withHeader: Want return with header or not.
 	//   - separator: Anything separator that want to use.
 	// Returns:
 	//   - Data with custom format using a specified separator.
-	ToCustom(withHeader bool, separator string) Output
+	ToCustom(withHeader bool, separator string) StreamingOutput
 
 	// AddColumn adds a new column with an empty value for all rows.
 	//
@@ -277,6 +278,740 @@ type BDataMatrix interface {
 	//     | 3  | alice | 28  |
 	//     +----+-------+-----+
 	PeekN(n int)
+
+	// ToCSVWith exports the matrix to CSV format with the given dialect, such as CSVExcel or CSVExcelEU.
+	//
+	// Parameters:
+	//   - opts: The options controlling delimiters, line endings, quoting and the header.
+	//
+	// Returns:
+	//   - CSV data in the given dialect.
+	ToCSVWith(opts CSVOptions) StreamingOutput
+
+	// ToJSONWith exports the matrix to JSON format with the given options.
+	//
+	// Parameters:
+	//   - opts: The options controlling the formatting and the orientation of the output.
+	//
+	// Returns:
+	//   - An array of row objects, or an object mapping every column to its values when opts.Columns is true.
+	ToJSONWith(opts JSONOptions) StreamingOutput
+
+	// ToJSONL exports the matrix to JSON Lines format, one JSON object per row with keys in header
+	// order. Null cells are exported as null.
+	//
+	// Returns:
+	//   - JSON Lines data, each line ending with a new line.
+	ToJSONL() StreamingOutput
+
+	// ToXML exports the matrix to XML format, with one element per row inside a root element. Header
+	// names are sanitized into valid XML names, and null cells are left out.
+	//
+	// Parameters:
+	//   - opts: The options controlling element names, attributes and indentation.
+	//
+	// Returns:
+	//   - XML data.
+	ToXML(opts XMLOptions) StreamingOutput
+
+	// ToSQL exports the matrix as a SQL script: a CREATE TABLE statement followed by batched
+	// multi-row INSERT statements. Column types follow the applied schema, and columns without one
+	// are text. Null cells, and empty values of columns that are not strings, are inserted as NULL.
+	// Times are converted to UTC.
+	//
+	// Parameters:
+	//   - table: The name of the table.
+	//   - opts: The options controlling the dialect, batch size and upserts.
+	//
+	// Returns:
+	//   - SQL statements, each ending with a semicolon and a new line. StreamingOutput.Err reports
+	//     an upsert without a primary key or an unknown dialect.
+	ToSQL(table string, opts SQLOptions) StreamingOutput
+
+	// WriteCSV writes the matrix to w in CSV format as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - withHeader: Want write with header or not.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteCSV(w io.Writer, withHeader bool) error
+
+	// WriteCSVWith writes the matrix to w in CSV format with the given dialect, as it is generated,
+	// without holding the whole export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - opts: The options controlling delimiters, line endings, quoting and the header.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteCSVWith(w io.Writer, opts CSVOptions) error
+
+	// WriteTSV writes the matrix to w in TSV format as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - withHeader: Want write with header or not.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteTSV(w io.Writer, withHeader bool) error
+
+	// WriteJSON writes the matrix to w in JSON format as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - opts: The options controlling the formatting and the orientation of the output.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteJSON(w io.Writer, opts JSONOptions) error
+
+	// WriteJSONL writes the matrix to w in JSON Lines format as it is generated, without holding the
+	// whole export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteJSONL(w io.Writer) error
+
+	// WriteXML writes the matrix to w in XML format as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - opts: The options controlling element names, attributes and indentation.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteXML(w io.Writer, opts XMLOptions) error
+
+	// WriteSQL writes the matrix to w as a SQL script as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - table: The name of the table.
+	//   - opts: The options controlling the dialect, batch size and upserts.
+	//
+	// Returns:
+	//   - An error if the options are invalid or writing fails.
+	WriteSQL(w io.Writer, table string, opts SQLOptions) error
+
+	// WriteToSQL inserts the matrix into a table of db inside a single transaction, creating the
+	// table first unless opts.OmitCreateTable is set. Rows are inserted in batches of opts.BatchSize,
+	// smaller when a batch would exceed the bind parameter limit of the dialect, through prepared
+	// statements with values typed by the applied schema. Nothing is written when any statement
+	// fails.
+	//
+	// Parameters:
+	//   - ctx: The context of the transaction.
+	//   - db: The database to write to.
+	//   - table: The name of the table.
+	//   - opts: The options controlling the dialect, batch size and upserts.
+	//
+	// Returns:
+	//   - An error if the options are invalid or a statement fails.
+	WriteToSQL(ctx context.Context, db *sql.DB, table string, opts SQLOptions) error
+
+	// WriteYAML writes the matrix to w in YAML format as it is generated, without holding the whole
+	// export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteYAML(w io.Writer) error
+
+	// WriteCustom writes the matrix to w in a custom format using a specified separator, as it is
+	// generated, without holding the whole export in memory.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//   - withHeader: Want write with header or not.
+	//   - separator: Anything separator that want to use.
+	//
+	// Returns:
+	//   - An error if writing fails.
+	WriteCustom(w io.Writer, withHeader bool, separator string) error
+
+	// InferSchema inspects the string cells and proposes a kind for each column.
+	//
+	// Parameters:
+	//   - sampleSize: The number of leading rows to inspect. Zero or less inspects every row.
+	//   - layouts: The candidate time layouts. DefaultTimeLayouts is used when none are provided.
+	//
+	// Returns:
+	//   - The proposed schema with confidence figures and counter-examples for each column.
+	InferSchema(sampleSize int, layouts ...string) Schema
+
+	// ApplySchema attaches a schema to the matrix. Once attached, AddRow, UpdateRow and
+	// UpdateRowColumn reject values that do not match the kind of their column.
+	//
+	// Parameters:
+	//   - schema: The schema to attach, usually the reviewed result of InferSchema.
+	//
+	// Returns:
+	//   - An error if a column does not exist, has no kind, or an existing value does not match its kind.
+	ApplySchema(schema Schema) error
+
+	// Schema returns the schema attached to the matrix.
+	//
+	// Returns:
+	//   - The attached schema.
+	//   - False if no schema has been applied.
+	Schema() (Schema, bool)
+
+	// Validate checks every row against the given rules and collects all violations.
+	//
+	// Parameters:
+	//   - rules: The rules to check, such as Required, Unique, OneOf or RowRule.
+	//
+	// Returns:
+	//   - A report listing every violation with its row index, column, value and rule name.
+	//   - An error if a rule refers to a column that does not exist.
+	Validate(rules ...Rule) (ValidationReport, error)
+
+	// SetPrimaryKey declares one or more columns as the primary key of the matrix. Once declared,
+	// rows can be addressed by key and AddRow, UpdateRow and UpdateRowColumn reject duplicate keys.
+	//
+	// Parameters:
+	//   - keys: The names of the key columns. No keys removes the primary key.
+	//
+	// Returns:
+	//   - An error if a column does not exist or existing rows hold duplicate keys.
+	SetPrimaryKey(keys ...string) error
+
+	// PrimaryKey returns the names of the primary key columns.
+	PrimaryKey() []string
+
+	// GetByKey retrieves a row by its primary key.
+	//
+	// Parameters:
+	//   - key: The values of the primary key columns, in declaration order.
+	//
+	// Returns:
+	//   - A copy of the values of the row holding the key, so changing it never changes the matrix.
+	//   - An error if no primary key is declared or the key does not exist.
+	GetByKey(key ...string) ([]string, error)
+
+	// UpdateByKey updates the row holding the given primary key.
+	//
+	// Parameters:
+	//   - key: The values of the primary key columns, in declaration order.
+	//   - values: The new values of the row.
+	//
+	// Returns:
+	//   - An error if the key does not exist or writing fails.
+	UpdateByKey(key []string, values ...string) error
+
+	// DeleteByKey removes the row holding the given primary key.
+	//
+	// Parameters:
+	//   - key: The values of the primary key columns, in declaration order.
+	//
+	// Returns:
+	//   - An error if no primary key is declared or the key does not exist.
+	DeleteByKey(key ...string) error
+
+	// Upsert updates the row holding the primary key found in values, or appends values as a new row.
+	//
+	// Parameters:
+	//   - values: The values of the row.
+	//
+	// Returns:
+	//   - An error if no primary key is declared or writing fails.
+	Upsert(values ...string) error
+
+	// Distinct returns a new matrix without duplicate rows, keeping the first row of each group of duplicates.
+	//
+	// Parameters:
+	//   - keys: The names of the columns to compare. No keys compares every column.
+	//
+	// Returns:
+	//   - A new matrix holding the distinct rows in their original order.
+	//   - An error if a column does not exist.
+	Distinct(keys ...string) (BDataMatrix, error)
+
+	// DistinctWith returns a new matrix without duplicate rows using the given options.
+	//
+	// Parameters:
+	//   - opts: The keep policy and comparison options.
+	//   - keys: The names of the columns to compare. No keys compares every column.
+	//
+	// Returns:
+	//   - A new matrix holding the kept rows in their original order.
+	//   - An error if a column does not exist.
+	DistinctWith(opts DistinctOptions, keys ...string) (BDataMatrix, error)
+
+	// Duplicates returns every row that has at least one duplicate.
+	//
+	// Parameters:
+	//   - keys: The names of the columns to compare. No keys compares every column.
+	//
+	// Returns:
+	//   - A new matrix holding the duplicated rows in their original order.
+	//   - The original indexes of the duplicated rows.
+	//   - An error if a column does not exist.
+	Duplicates(keys ...string) (BDataMatrix, []int, error)
+
+	// DuplicatesWith returns every row that has at least one duplicate using the given options.
+	// The keep policy of opts is ignored.
+	//
+	// Parameters:
+	//   - opts: The comparison options.
+	//   - keys: The names of the columns to compare. No keys compares every column.
+	//
+	// Returns:
+	//   - A new matrix holding the duplicated rows in their original order.
+	//   - The original indexes of the duplicated rows.
+	//   - An error if a column does not exist.
+	DuplicatesWith(opts DistinctOptions, keys ...string) (BDataMatrix, []int, error)
+
+	// ApplyChangeset patches the matrix with a changeset returned by Diff. Either every change is
+	// applied or, on error, the matrix is left untouched. Rows are matched by the primary key of the
+	// matrix when the changeset has no key.
+	//
+	// Parameters:
+	//   - changeset: The changes to apply.
+	//
+	// Returns:
+	//   - An error if a row key does not exist, a changed cell no longer holds its old value, or
+	//     neither the changeset nor the matrix has a key.
+	ApplyChangeset(changeset Changeset) error
+
+	// Window groups rows into partitions ordered by sort keys, to add columns computed by window functions.
+	//
+	// Parameters:
+	//   - partitionBy: The names of the columns to partition by. No columns makes a single partition.
+	//   - orderBy: The sort keys ordering the rows of each partition. No keys keeps the row order.
+	//
+	// Returns:
+	//   - A window whose Add method appends the result of a window function as a new column.
+	Window(partitionBy []string, orderBy []SortKey) *Window
+
+	// AddComputedColumn adds a new column whose values are computed from an expression evaluated on each row.
+	//
+	// The expression language supports number and quoted string literals, column references
+	// (bare names or bracketed names such as [Unit Price]), arithmetic (+ - * / %, where + adds two
+	// numbers and concatenates any other values), comparisons (== != < <= > >=), logic (&& || !)
+	// and the functions if, coalesce, concat, upper, lower, trim, len, substr (zero-based),
+	// parse_date (to RFC 3339) and format_date (from RFC 3339).
+	//
+	// Example usage:
+	//
+	//	_ = matrix.AddComputedColumn("Total", "Price * Qty")
+	//	_ = matrix.AddComputedColumn("FullName", `First + " " + Last`)
+	//
+	// Parameters:
+	//   - key: The naming of column want to be added.
+	//   - expr: The expression computing the value of each row.
+	//
+	// Returns:
+	//   - An error if the expression is invalid, cannot be evaluated on a row or the column cannot be added.
+	AddComputedColumn(key, expr string) error
+
+	// AddComputedColumnFunc adds a new column whose values are computed by a function called on each row.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be added.
+	//   - fn: The function computing the value of each row.
+	//
+	// Returns:
+	//   - An error if fn fails on a row or the column cannot be added.
+	AddComputedColumnFunc(key string, fn func(row Row) (string, error)) error
+
+	// Apply transforms every value of a column in place. Values that fail to transform are left
+	// unchanged and reported, while the other values are still transformed.
+	//
+	// Example usage:
+	//
+	//	err := matrix.Apply("Name", Chain(CollapseSpace(), Title()))
+	//
+	// Parameters:
+	//   - key: The naming of column want to be transformed.
+	//   - fn: The transformation, such as Trim, Upper or a custom function.
+	//
+	// Returns:
+	//   - An error if the column does not exist, or the joined CellError of every failed value.
+	Apply(key string, fn TransformFunc) error
+
+	// ApplyAll transforms every value of the matrix in place. Values that fail to transform are left
+	// unchanged and reported, while the other values are still transformed.
+	//
+	// Parameters:
+	//   - fn: The transformation, such as Trim, Upper or a custom function.
+	//
+	// Returns:
+	//   - The joined CellError of every failed value.
+	ApplyAll(fn TransformFunc) error
+
+	// SetNull marks a cell as null. A null cell reads as an empty string, but is distinct from
+	// an empty value: IsNull reports it, and ToJSON and ToYAML export it as null.
+	// Writing a value to the cell clears the null mark.
+	//
+	// Parameters:
+	//   - index: The index of the row.
+	//   - key: The naming of columns.
+	//
+	// Returns:
+	//   - An error if the cell does not exist or the column is part of the primary key.
+	SetNull(index int, key string) error
+
+	// IsNull reports whether a cell is null.
+	//
+	// Parameters:
+	//   - index: The index of the row.
+	//   - key: The naming of columns.
+	//
+	// Returns:
+	//   - True if the cell is null.
+	//   - An error if the cell does not exist.
+	IsNull(index int, key string) (bool, error)
+
+	// FillNull replaces every null cell of a column with a value.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be filled.
+	//   - value: The value to write.
+	//
+	// Returns:
+	//   - An error if the column does not exist or writing fails.
+	FillNull(key string, value string) error
+
+	// FillForward replaces every null cell of a column with the closest previous non-null value.
+	// Leading null cells are left untouched.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be filled.
+	//
+	// Returns:
+	//   - An error if the column does not exist or writing fails.
+	FillForward(key string) error
+
+	// FillBackward replaces every null cell of a column with the closest next non-null value.
+	// Trailing null cells are left untouched.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be filled.
+	//
+	// Returns:
+	//   - An error if the column does not exist or writing fails.
+	FillBackward(key string) error
+
+	// FillMode replaces every null cell of a column with the most frequent non-empty value of the
+	// column.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be filled.
+	//
+	// Returns:
+	//   - An error if the column does not exist or writing fails.
+	FillMode(key string) error
+
+	// FillMean replaces every null cell of a column with the mean of the non-empty values of the column.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be filled.
+	//
+	// Returns:
+	//   - An error if the column does not exist, holds a value that is not a number or writing fails.
+	FillMean(key string) error
+
+	// DropNullRows removes every row holding a null cell in any of the given columns.
+	//
+	// Parameters:
+	//   - keys: The names of the columns to check. No keys checks every column.
+	//
+	// Returns:
+	//   - An error if a column does not exist.
+	DropNullRows(keys ...string) error
+
+	// DeleteNullColumns removes every column whose cells are all null. Unlike DeleteEmptyColumns,
+	// columns holding empty values are kept.
+	DeleteNullColumns() error
+
+	// RenameColumn renames a column. The schema, primary key and aliases of the column follow the new name.
+	//
+	// Parameters:
+	//   - old: The current name of the column.
+	//   - new: The new name of the column.
+	//
+	// Returns:
+	//   - An error if the column does not exist or the new name is already used.
+	RenameColumn(old, new string) error
+
+	// RenameColumns renames multiple columns at once, so names can be swapped.
+	//
+	// Parameters:
+	//   - renames: The new names of the columns, keyed by their current names.
+	//
+	// Returns:
+	//   - An error if a column does not exist or a new name is already used.
+	RenameColumns(renames map[string]string) error
+
+	// ReorderColumns moves the given columns to the front of the header, in the given order.
+	// The other columns follow in their current order.
+	//
+	// Parameters:
+	//   - keys: The names of the columns in their new order.
+	//
+	// Returns:
+	//   - An error if a column does not exist or is listed twice.
+	ReorderColumns(keys ...string) error
+
+	// MoveColumn moves a column to a new position in the header.
+	//
+	// Parameters:
+	//   - key: The naming of column want to be moved.
+	//   - position: The zero-based index of the column after the move.
+	//
+	// Returns:
+	//   - An error if the column does not exist or the position is out of range.
+	MoveColumn(key string, position int) error
+
+	// AddColumnAlias adds an alternative name resolving to a column, for example to keep the old
+	// name of a renamed column working during a migration. Aliases are accepted wherever a column
+	// name is expected, but do not appear in the header.
+	//
+	// Parameters:
+	//   - alias: The alternative name.
+	//   - key: The naming of column the alias resolves to.
+	//
+	// Returns:
+	//   - An error if the column does not exist or the alias is already used.
+	AddColumnAlias(alias, key string) error
+
+	// RemoveColumnAlias removes an alias added by AddColumnAlias.
+	//
+	// Parameters:
+	//   - alias: The alternative name to remove.
+	//
+	// Returns:
+	//   - An error if the alias does not exist.
+	RemoveColumnAlias(alias string) error
+
+	// ColumnAliases returns the aliases of the matrix, mapped to the names of their columns.
+	ColumnAliases() map[string]string
+
+	// Transpose turns the rows of the matrix into columns. The first column of the result holds the
+	// former header names and is named after headerFrom, and the other columns are named after the
+	// values of headerFrom, with the headerFrom column itself left out. Transposing the result by the
+	// same column restores the original matrix.
+	//
+	// When headerFrom is empty, the first column is named TransposeNameColumn and the other columns
+	// are named "Row0", "Row1", ... after the row indexes. Empty names are replaced by the generated
+	// name of their row, and duplicate names get a "_2", "_3", ... suffix.
+	// The schema, primary key and aliases are not carried over.
+	//
+	// Parameters:
+	//   - headerFrom: The naming of column holding the new header names, or empty to generate them.
+	//
+	// Returns:
+	//   - The transposed matrix.
+	//   - An error if the column does not exist.
+	Transpose(headerFrom string) (BDataMatrix, error)
+
+	// Untranspose reverses Transpose, turning the first column of the matrix back into the header.
+	// The current header names become the first column, unless they are the generated "Row0", "Row1",
+	// ... names, in which case they are dropped.
+	//
+	// Returns:
+	//   - The untransposed matrix.
+	//   - An error if the result cannot be built.
+	Untranspose() (BDataMatrix, error)
+
+	// Concat returns a new matrix holding the rows of the matrix followed by the rows of the other
+	// matrices, with columns aligned according to mode. The schema, primary key and aliases are not
+	// carried over.
+	//
+	// Parameters:
+	//   - mode: The column alignment mode, such as ConcatStrict or ConcatByName.
+	//   - others: The matrices whose rows are appended, in order.
+	//
+	// Returns:
+	//   - The concatenated matrix.
+	//   - An error if the headers cannot be aligned with the given mode.
+	Concat(mode ConcatMode, others ...BDataMatrix) (BDataMatrix, error)
+
+	// ConcatWith returns a new matrix holding the rows of the matrix followed by the rows of the other
+	// matrices using the given options, for example to record which matrix each row came from.
+	//
+	// Parameters:
+	//   - opts: The options controlling column alignment and the source column.
+	//   - others: The matrices whose rows are appended, in order.
+	//
+	// Returns:
+	//   - The concatenated matrix.
+	//   - An error if the headers cannot be aligned or the source column name is already used.
+	ConcatWith(opts ConcatOptions, others ...BDataMatrix) (BDataMatrix, error)
+
+	// Sample returns a new matrix holding n rows picked at random without replacement, in their
+	// original order. The same seed always picks the same rows. The rows are copied, so changing
+	// the sample never changes the matrix.
+	//
+	// Parameters:
+	//   - n: The number of rows to pick.
+	//   - seed: The seed of the random number generator.
+	//
+	// Returns:
+	//   - The sampled matrix.
+	//   - An error if n is negative or greater than the number of rows.
+	Sample(n int, seed int64) (BDataMatrix, error)
+
+	// SampleFraction returns a new matrix holding a fraction of the rows picked at random without
+	// replacement, in their original order. The number of rows is rounded to the nearest integer.
+	//
+	// Parameters:
+	//   - f: The fraction of rows to pick, between 0 and 1.
+	//   - seed: The seed of the random number generator.
+	//
+	// Returns:
+	//   - The sampled matrix.
+	//   - An error if f is not between 0 and 1.
+	SampleFraction(f float64, seed int64) (BDataMatrix, error)
+
+	// SampleStratified returns a new matrix holding the same fraction of the rows of every distinct
+	// value of a column, so the proportions of the values are kept. Rows keep their original order
+	// and are copied, like Sample.
+	//
+	// Parameters:
+	//   - key: The naming of column whose values define the strata.
+	//   - f: The fraction of rows to pick from each stratum, between 0 and 1.
+	//   - seed: The seed of the random number generator.
+	//
+	// Returns:
+	//   - The sampled matrix.
+	//   - An error if the column does not exist or f is not between 0 and 1.
+	SampleStratified(key string, f float64, seed int64) (BDataMatrix, error)
+
+	// Shuffle rearranges the rows in a random order. The same seed always gives the same order.
+	//
+	// Parameters:
+	//   - seed: The seed of the random number generator.
+	Shuffle(seed int64)
+
+	// Split divides the rows at random into disjoint matrices, for example to build training and test
+	// sets. Rows keep their original order within each matrix and are copied, so changing a matrix
+	// never changes the others or the original.
+	//
+	// Parameters:
+	//   - ratios: The relative size of each matrix, such as []float64{0.8, 0.2}. Ratios are
+	//     normalized by their sum.
+	//   - seed: The seed of the random number generator.
+	//
+	// Returns:
+	//   - One matrix per ratio, together holding every row exactly once.
+	//   - An error if no ratio is given or a ratio is negative.
+	Split(ratios []float64, seed int64) ([]BDataMatrix, error)
+
+	// Slice returns a view of the rows from start to end, excluding end. The view holds a copy of
+	// the rows, so changing it never changes the matrix. It has no primary key or schema.
+	//
+	// Parameters:
+	//   - start: The index of the first row of the view.
+	//   - end: The index following the last row of the view.
+	//
+	// Returns:
+	//   - The view of the rows.
+	//   - An error if start or end is out of range, or end is before start.
+	Slice(start, end int) (BDataMatrix, error)
+
+	// Head returns a view of the first n rows, or of every row when the matrix has fewer, like
+	// Slice.
+	//
+	// Parameters:
+	//   - n: The maximum number of rows of the view.
+	Head(n int) BDataMatrix
+
+	// Tail returns a view of the last n rows, or of every row when the matrix has fewer, like
+	// Slice.
+	//
+	// Parameters:
+	//   - n: The maximum number of rows of the view.
+	Tail(n int) BDataMatrix
+
+	// Page returns a view of one page of rows, like an offset/limit query, together with the total
+	// number of rows and pages. Pages past the last one are empty. The page is a view like Slice.
+	//
+	// Parameters:
+	//   - pageNum: The one-based number of the page.
+	//   - pageSize: The maximum number of rows per page.
+	//
+	// Returns:
+	//   - The page of rows and its metadata.
+	//   - An error if pageNum or pageSize is less than 1.
+	Page(pageNum, pageSize int) (Page, error)
+
+	// PageAfter returns the rows following a cursor in the order given by orderBy, up to limit rows.
+	// Unlike Page, the rows of later pages do not shift when rows are added or removed before the
+	// cursor. The sort keys should identify rows uniquely, for example by ending with the primary
+	// key, since rows sorting equal to the last row of a page are skipped. The page is a view like
+	// Slice.
+	//
+	// Parameters:
+	//   - orderBy: The sort keys ordering the rows.
+	//   - cursor: The NextCursor of the previous page, or empty for the first page.
+	//   - limit: The maximum number of rows of the page.
+	//
+	// Returns:
+	//   - The page of rows and the cursor of the next page.
+	//   - An error if a column does not exist, limit is less than 1 or the cursor is invalid.
+	PageAfter(orderBy []SortKey, cursor string, limit int) (CursorPage, error)
+
+	// DeleteWhere deletes every row matching a query in a single pass.
+	//
+	// Parameters:
+	//   - query: Have struct FindRowsQuery need to be filled.
+	//
+	// Returns:
+	//   - The number of deleted rows. No matching row is not an error.
+	//   - An error if the column of the query does not exist.
+	DeleteWhere(query FindRowsQuery) (int, error)
+
+	// DeleteRows deletes the rows at the given indexes in a single pass. Indexes refer to the rows
+	// before the deletion, so they do not shift while rows are removed. Repeated indexes are deleted once.
+	//
+	// Parameters:
+	//   - indexes: The indexes of the rows to delete.
+	//
+	// Returns:
+	//   - The number of deleted rows.
+	//   - An error if an index is out of range, in which case no row is deleted.
+	DeleteRows(indexes ...int) (int, error)
+
+	// UpdateWhere sets a column to the same value in every row matching a query. Either every
+	// matching row is updated or none is.
+	//
+	// Parameters:
+	//   - query: Have struct FindRowsQuery need to be filled.
+	//   - key: The naming of column want to be updated.
+	//   - value: The new value.
+	//
+	// Returns:
+	//   - The number of updated rows.
+	//   - An error if a column does not exist or the value breaks the schema or the primary key.
+	UpdateWhere(query FindRowsQuery, key string, value string) (int, error)
+
+	// UpdateWhereFunc replaces every row matching a query with the values returned by fn. Either
+	// every matching row is updated or none is.
+	//
+	// Parameters:
+	//   - query: Have struct FindRowsQuery need to be filled.
+	//   - fn: The function returning the new values of a row, in header order.
+	//
+	// Returns:
+	//   - The number of updated rows.
+	//   - An error if the column of the query does not exist, fn fails or a new row is invalid.
+	UpdateWhereFunc(query FindRowsQuery, fn func(row Row) ([]string, error)) (int, error)
 }
 
 // New create a new BDataMatrix with the provided headers.
@@ -357,6 +1092,9 @@ func NewWithData(rows [][]string, keys ...string) (BDataMatrix, error) {
 
 // Output defines methods for exporting matrix data.
 //
+// The data reflects the matrix when the Output is created: changes made to the matrix afterwards are
+// not part of it.
+//
 // Example usage:
 //
 //	// Get CSV output and write to file.
@@ -370,7 +1108,9 @@ func NewWithData(rows [][]string, keys ...string) (BDataMatrix, error) {
 //	jsonOut := matrix.ToJSON(true, false)
 //	fmt.Println(jsonOut.String())
 type Output interface {
-	// Write writes the output data to a file with the given name and file mode.
+	// Write writes the output data to a file with the given name and file mode. The data is compressed
+	// when the file extension is ".gz", ".zz" or ".zlib". If generating or writing the data fails, the
+	// file is removed so that a partial export is not left behind.
 	//
 	// Parameters:
 	//   - name: The filename to write to.
@@ -383,16 +1123,80 @@ type Output interface {
 	// Bytes returns the output data as a byte slice.
 	//
 	// Returns:
-	//   - A []byte containing the output data.
+	//   - A []byte containing the output data, or nil if generating it fails.
 	Bytes() []byte
 
 	// String returns the output data as a string.
 	//
 	// Returns:
-	//   - A string representation of the output data.
+	//   - A string representation of the output data, or an empty string if generating it fails.
 	String() string
 }
 
+// StreamingOutput is an Output that can also be streamed, and that reports why generating its data
+// failed. Every To method of a matrix returns one.
+//
+// The data is generated when it is first consumed rather than when the output is created, and
+// Bytes and String keep it once generated. WriteTo and Read generate it as it is consumed, holding
+// only a small part of it in memory at once, unless Bytes or String already generated it.
+//
+// So that later changes to the matrix are not part of it, the output holds a copy of the rows,
+// taken when it is created. The cell values themselves are shared rather than copied. To export a
+// large matrix without copying its rows, use the Write methods of the matrix, such as WriteCSV,
+// which read the matrix as they write.
+//
+// Example usage:
+//
+//	// Stream CSV output to an HTTP response.
+//	_, err := io.Copy(w, matrix.ToCSV(true))
+type StreamingOutput interface {
+	Output
+
+	// WriteWith writes the output data to a file with the given name, file mode and options, for
+	// example to write it atomically or compressed.
+	//
+	// Parameters:
+	//   - name: The filename to write to.
+	//   - mode: The file mode (permissions) to use when writing.
+	//   - opts: The options controlling compression, atomicity, parent directories and overwriting.
+	//
+	// Returns:
+	//   - An error if writing fails, or if the file exists and opts.NoOverwrite is set.
+	WriteWith(name string, mode os.FileMode, opts WriteOptions) error
+
+	// Read reads the next bytes of the output data, generating it step by step as it is consumed. It
+	// implements io.Reader. Read consumes the output once; the other methods can be called any number
+	// of times.
+	//
+	// Parameters:
+	//   - p: The buffer to read into.
+	//
+	// Returns:
+	//   - The number of bytes read.
+	//   - io.EOF once all the output data has been read.
+	Read(p []byte) (int, error)
+
+	// WriteTo writes the output data to w as it is generated. It implements io.WriterTo, so io.Copy
+	// uses it.
+	//
+	// Parameters:
+	//   - w: The writer to write to, such as a file, an HTTP response or a pipe.
+	//
+	// Returns:
+	//   - The number of bytes written.
+	//   - An error if generating or writing fails.
+	WriteTo(w io.Writer) (int64, error)
+
+	// Err returns the error hit by the last generation or write of the output data, or nil if it
+	// succeeded. When the data has not been generated yet, it is generated as by Bytes to find out.
+	// Bytes and String return no data when generation fails, so check Err to tell a failed export
+	// from an empty one.
+	//
+	// Returns:
+	//   - The error of the last generation or write, or nil.
+	Err() error
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
 // BDataMatrix Implementation
 // ---------------------------------------------------------------------------------------------------------------------
@@ -401,13 +1205,30 @@ type bDataMatrix struct {
 	header      []string
 	rows        [][]string
 	headerIndex map[string]int
+	schema      *Schema
+	primaryKey  []string
+	keyIndex    map[string]int
+	nulls       [][]bool
+	aliases     map[string]string
 }
 
 func (t *bDataMatrix) AddRow(values ...string) error {
 	if len(values) != t.LenColumns() {
 		return fmt.Errorf("row length (%d) does not match header length (%d)", len(values), t.LenColumns())
 	}
+	if err := t.checkSchema(values); err != nil {
+		return err
+	}
+	if err := t.checkKey(-1, values); err != nil {
+		return err
+	}
 	t.rows = append(t.rows, values)
+	if t.nulls != nil {
+		t.nulls = append(t.nulls, nil)
+	}
+	if t.keyIndex != nil {
+		t.keyIndex[t.rowKey(values)] = len(t.rows) - 1
+	}
 	return nil
 }
 
@@ -448,6 +1269,7 @@ func (t *bDataMatrix) AddColumnWithValue(key string, value ...string) error {
 			t.rows[i] = append(t.rows[i], value[i])
 		}
 	}
+	t.appendNullColumn()
 	return t.calculateHeaderIndex()
 }
 
@@ -459,6 +1281,7 @@ func (t *bDataMatrix) AddColumnWithDefaultValue(defaultValue, key string) error
 	for i := range t.rows {
 		t.rows[i] = append(t.rows[i], defaultValue)
 	}
+	t.appendNullColumn()
 	return t.calculateHeaderIndex()
 }
 
@@ -498,7 +1321,12 @@ func (t *bDataMatrix) GetRows(indexes ...int) (BDataMatrix, error) {
 		}
 		rows[i] = row
 	}
-	return NewWithData(rows, t.header...)
+	nm, err := NewWithData(rows, t.header...)
+	if err != nil {
+		return nil, err
+	}
+	nm.(*bDataMatrix).nulls = t.selectNulls(indexes, nil)
+	return nm, nil
 }
 
 func (t *bDataMatrix) GetColumn(key string) ([]string, error) {
@@ -514,19 +1342,30 @@ func (t *bDataMatrix) GetColumn(key string) ([]string, error) {
 }
 
 func (t *bDataMatrix) GetColumns(keys ...string) (BDataMatrix, error) {
+	idxs := make([]int, len(keys))
+	for j, key := range keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+		idxs[j] = idx
+	}
 	newRows := make([][]string, t.LenRows())
+	indexes := make([]int, t.LenRows())
 	for i, row := range t.rows {
 		newRow := make([]string, len(keys))
-		for j, key := range keys {
-			idx, exists := t.headerIndex[key]
-			if !exists {
-				return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
-			}
+		for j, idx := range idxs {
 			newRow[j] = row[idx]
 		}
 		newRows[i] = newRow
+		indexes[i] = i
+	}
+	nm, err := NewWithData(newRows, keys...)
+	if err != nil {
+		return nil, err
 	}
-	return NewWithData(newRows, keys...)
+	nm.(*bDataMatrix).nulls = t.selectNulls(indexes, idxs)
+	return nm, nil
 }
 
 func (t *bDataMatrix) UpdateRow(index int, values ...string) error {
@@ -536,7 +1375,20 @@ func (t *bDataMatrix) UpdateRow(index int, values ...string) error {
 	if len(values) != t.LenColumns() {
 		return fmt.Errorf("row length (%d) does not match header length (%d)", len(values), t.LenColumns())
 	}
+	if err := t.checkSchema(values); err != nil {
+		return err
+	}
+	if err := t.checkKey(index, values); err != nil {
+		return err
+	}
+	if t.keyIndex != nil {
+		delete(t.keyIndex, t.rowKey(t.rows[index]))
+		t.keyIndex[t.rowKey(values)] = index
+	}
 	t.rows[index] = values
+	if t.nulls != nil {
+		t.nulls[index] = nil
+	}
 	return nil
 }
 
@@ -548,7 +1400,22 @@ func (t *bDataMatrix) UpdateRowColumn(index int, key string, value string) error
 	if index < 0 || index >= t.LenRows() {
 		return fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index)
 	}
+	// The key may be an alias, so the checks use the name of the column.
+	key = t.header[idx]
+	if err := t.checkSchemaValue(key, value); err != nil {
+		return err
+	}
+	if t.isKeyColumn(key) {
+		values := append([]string(nil), t.rows[index]...)
+		values[idx] = value
+		if err := t.checkKey(index, values); err != nil {
+			return err
+		}
+		delete(t.keyIndex, t.rowKey(t.rows[index]))
+		t.keyIndex[t.rowKey(values)] = index
+	}
 	t.rows[index][idx] = value
+	t.markNull(index, idx, false)
 	return nil
 }
 
@@ -557,6 +1424,10 @@ func (t *bDataMatrix) DeleteRow(index int) error {
 		return fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index)
 	}
 	t.rows = append(t.rows[:index], t.rows[index+1:]...)
+	if t.nulls != nil {
+		t.nulls = append(t.nulls[:index], t.nulls[index+1:]...)
+	}
+	_ = t.reindexKeys()
 	return nil
 }
 
@@ -568,14 +1439,24 @@ func (t *bDataMatrix) DeleteColumn(key string) error {
 	if t.LenColumns() == 1 {
 		return ErrDeleteLastColumn
 	}
+	key = t.header[idx]
+	if t.isKeyColumn(key) {
+		return fmt.Errorf("%w: %s", ErrPrimaryKeyColumn, key)
+	}
 	newHeader := append(t.header[:idx], t.header[idx+1:]...)
 	newRows := make([][]string, t.LenRows())
 	for i, row := range t.rows {
 		newRows[i] = append(row[:idx], row[idx+1:]...)
 	}
+	keep := make([]bool, t.LenColumns())
+	for i := range keep {
+		keep[i] = i != idx
+	}
+	t.keepNullColumns(keep)
 	t.header = newHeader
 	t.rows = newRows
 	_ = t.calculateHeaderIndex()
+	t.pruneSchema()
 	return nil
 }
 
@@ -588,6 +1469,9 @@ func (t *bDataMatrix) DeleteEmptyColumns() error {
 			}
 		}
 	}
+	for _, key := range t.primaryKey {
+		nonEmptyColumns[t.headerIndex[key]] = true
+	}
 	var newHeader []string
 	for i, col := range t.header {
 		if nonEmptyColumns[i] {
@@ -607,9 +1491,11 @@ func (t *bDataMatrix) DeleteEmptyColumns() error {
 		}
 		newRows[i] = newRow
 	}
+	t.keepNullColumns(nonEmptyColumns)
 	t.header = newHeader
 	t.rows = newRows
 	_ = t.calculateHeaderIndex()
+	t.pruneSchema()
 	return nil
 }
 
@@ -656,6 +1542,22 @@ type FindRowsQuery struct {
 }
 
 func (t *bDataMatrix) FindRows(query FindRowsQuery) (BDataMatrix, error) {
+	matchedIndexes, err := t.matchRows(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchedIndexes) == 0 {
+		return nil, fmt.Errorf("%w: no rows found where column '%s' matches criteria", ErrNoRowsFound, query.Column)
+	}
+	nm, err := t.GetRows(matchedIndexes...)
+	if err != nil {
+		return nil, err
+	}
+	return nm, nil
+}
+
+// matchRows returns the indexes of the rows matching the query, in ascending order.
+func (t *bDataMatrix) matchRows(query FindRowsQuery) ([]int, error) {
 	cVals, err := t.GetColumn(query.Column)
 	if err != nil {
 		return nil, err
@@ -690,18 +1592,12 @@ func (t *bDataMatrix) FindRows(query FindRowsQuery) (BDataMatrix, error) {
 			}
 		}
 	}
-	if len(matchedIndexesUnique) == 0 {
-		return nil, fmt.Errorf("%w: no rows found where column '%s' matches criteria", ErrNoRowsFound, query.Column)
-	}
-	var matchedIndexes []int
+	matchedIndexes := make([]int, 0, len(matchedIndexesUnique))
 	for idx := range matchedIndexesUnique {
 		matchedIndexes = append(matchedIndexes, idx)
 	}
-	nm, err := t.GetRows(matchedIndexes...)
-	if err != nil {
-		return nil, err
-	}
-	return nm, nil
+	sort.Ints(matchedIndexes)
+	return matchedIndexes, nil
 }
 
 func (t *bDataMatrix) sortBy(isAsc bool, keys ...string) error {
@@ -713,18 +1609,24 @@ func (t *bDataMatrix) sortBy(isAsc bool, keys ...string) error {
 			return fmt.Errorf("%w: %s", ErrColumnNotFound, h)
 		}
 	}
-	sort.SliceStable(t.rows, func(i, j int) bool {
+	order := make([]int, t.LenRows())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := t.rows[order[i]], t.rows[order[j]]
 		for _, h := range keys {
 			idx := t.headerIndex[h]
-			if t.rows[i][idx] != t.rows[j][idx] {
+			if a[idx] != b[idx] {
 				if isAsc {
-					return t.rows[i][idx] < t.rows[j][idx]
+					return a[idx] < b[idx]
 				}
-				return t.rows[i][idx] > t.rows[j][idx]
+				return a[idx] > b[idx]
 			}
 		}
 		return false
 	})
+	t.reorderRows(order)
 	return nil
 }
 
@@ -787,10 +1689,37 @@ func (t *bDataMatrix) Copy() BDataMatrix {
 	for key, value := range t.headerIndex {
 		newHeaderIndex[key] = value
 	}
+	var newSchema *Schema
+	if t.schema != nil {
+		newSchema = &Schema{Columns: append([]ColumnSchema(nil), t.schema.Columns...)}
+	}
+	var newAliases map[string]string
+	if t.aliases != nil {
+		newAliases = make(map[string]string, len(t.aliases))
+		for alias, key := range t.aliases {
+			newAliases[alias] = key
+		}
+	}
+	var newKeyIndex map[string]int
+	if t.keyIndex != nil {
+		newKeyIndex = make(map[string]int, len(t.keyIndex))
+		for key, value := range t.keyIndex {
+			newKeyIndex[key] = value
+		}
+	}
+	indexes := make([]int, t.LenRows())
+	for i := range indexes {
+		indexes[i] = i
+	}
 	return &bDataMatrix{
 		header:      newHeader,
 		rows:        newRows,
 		headerIndex: newHeaderIndex,
+		schema:      newSchema,
+		primaryKey:  append([]string(nil), t.primaryKey...),
+		keyIndex:    newKeyIndex,
+		nulls:       t.selectNulls(indexes, nil),
+		aliases:     newAliases,
 	}
 }
 
@@ -804,6 +1733,8 @@ func (t *bDataMatrix) LenRows() int {
 
 func (t *bDataMatrix) Clear() {
 	t.rows = [][]string{}
+	t.nulls = nil
+	_ = t.reindexKeys()
 }
 
 func (t *bDataMatrix) Peek() {
@@ -858,59 +1789,24 @@ func (t *bDataMatrix) PeekN(n int) {
 	}
 }
 
-func (t *bDataMatrix) ToCSV(withHeader bool) Output {
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
-	defer writer.Flush()
-	if err := writer.WriteAll(t.Data(withHeader)); err != nil {
-		return &outputData{data: []byte(fmt.Sprintf("error writing CSV: %v", err))}
-	}
-	return &outputData{data: buf.Bytes()}
+func (t *bDataMatrix) ToCSV(withHeader bool) StreamingOutput {
+	return t.ToCSVWith(CSVOptions{OmitHeader: !withHeader})
 }
 
-func (t *bDataMatrix) ToTSV(withHeader bool) Output {
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
-	defer writer.Flush()
-	writer.Comma = '\t'
-	if err := writer.WriteAll(t.Data(withHeader)); err != nil {
-		return &outputData{data: []byte(fmt.Sprintf("error writing TSV: %v", err))}
-	}
-	return &outputData{data: buf.Bytes()}
+func (t *bDataMatrix) ToTSV(withHeader bool) StreamingOutput {
+	return t.ToCSVWith(CSVOptions{Delimiter: '\t', OmitHeader: !withHeader})
 }
 
-func (t *bDataMatrix) ToJSON(compact bool) Output {
-	var output []byte
-	var err error
-	if compact {
-		output, err = json.Marshal(t.DataMap())
-	} else {
-		output, err = json.MarshalIndent(t.DataMap(), "", "  ")
-	}
-	if err != nil {
-		return nil
-	}
-	return &outputData{data: output}
+func (t *bDataMatrix) ToJSON(compact bool) StreamingOutput {
+	return t.ToJSONWith(JSONOptions{Compact: compact})
 }
 
-func (t *bDataMatrix) ToYAML() Output {
-	output, err := yaml.Marshal(t.DataMap())
-	if err != nil {
-		return nil
-	}
-	return &outputData{data: output}
+func (t *bDataMatrix) ToYAML() StreamingOutput {
+	return &outputData{stream: t.snapshot().yamlStream()}
 }
 
-func (t *bDataMatrix) ToCustom(withHeader bool, separator string) Output {
-	var sb strings.Builder
-	rows := t.Data(withHeader)
-	for i, row := range rows {
-		sb.WriteString(strings.Join(row, separator))
-		if i < len(rows)-1 {
-			sb.WriteString("\n")
-		}
-	}
-	return &outputData{data: []byte(sb.String())}
+func (t *bDataMatrix) ToCustom(withHeader bool, separator string) StreamingOutput {
+	return &outputData{stream: t.snapshot().customStream(withHeader, separator)}
 }
 
 func (t *bDataMatrix) ContainsValue(key string, value string) (bool, error) {
@@ -936,6 +1832,15 @@ func (t *bDataMatrix) calculateHeaderIndex() error {
 		}
 		return fmt.Errorf("%w: %s", ErrDuplicateHeader, h)
 	}
+	// Aliases resolve to the index of their column. Aliases of deleted columns are dropped.
+	for alias, key := range t.aliases {
+		idx, ok := t.headerIndex[key]
+		if !ok {
+			delete(t.aliases, alias)
+			continue
+		}
+		t.headerIndex[alias] = idx
+	}
 	return nil
 }
 
@@ -943,20 +1848,85 @@ func (t *bDataMatrix) calculateHeaderIndex() error {
 // Output Implementation
 // ---------------------------------------------------------------------------------------------------------------------
 
+// outputData generates its data lazily from a stream. Bytes keeps the generated data, while WriteTo
+// and Read generate it step by step as it is consumed.
 type outputData struct {
-	data []byte
+	stream stream
+	// cached, data and dataErr hold the result of generating the data in memory.
+	cached  bool
+	data    []byte
+	dataErr error
+	// used and err hold the result of the last generation or write, reported by Err.
+	used bool
+	err  error
+	// buf, next and readErr hold the state of Read.
+	buf     bytes.Buffer
+	next    func() error
+	readErr error
 }
 
 func (o *outputData) Write(name string, mode os.FileMode) error {
-	return os.WriteFile(name, o.data, mode)
+	return o.WriteWith(name, mode, WriteOptions{})
 }
 
 func (o *outputData) Bytes() []byte {
+	if !o.cached {
+		var buf bytes.Buffer
+		if o.dataErr = runStream(o.stream, &buf); o.dataErr == nil {
+			o.data = buf.Bytes()
+		}
+		o.cached = true
+	}
+	o.used, o.err = true, o.dataErr
 	return o.data
 }
 
 func (o *outputData) String() string {
-	return string(o.data)
+	return string(o.Bytes())
+}
+
+func (o *outputData) Err() error {
+	if !o.used {
+		o.Bytes()
+	}
+	return o.err
+}
+
+func (o *outputData) Read(p []byte) (int, error) {
+	if o.next == nil {
+		o.next = o.stream(&o.buf)
+	}
+	for o.buf.Len() == 0 {
+		if o.readErr != nil {
+			return 0, o.readErr
+		}
+		o.readErr = o.next()
+		if o.readErr != nil {
+			o.used = true
+			o.err = o.readErr
+			if o.err == io.EOF {
+				o.err = nil
+			} else {
+				// Data generated before the failure is dropped, so it cannot be mistaken for a complete export.
+				o.buf.Reset()
+			}
+		}
+	}
+	return o.buf.Read(p)
+}
+
+func (o *outputData) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	s := o.stream
+	if o.cached {
+		s = bytesStream(o.data)
+		if o.dataErr != nil {
+			s = errorStream(o.dataErr)
+		}
+	}
+	err := runStream(s, cw)
+	o.used, o.err = true, err
+	return cw.n, err
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,95 @@
+package bdatamatrix
+
+import "fmt"
+
+func (t *bDataMatrix) DeleteWhere(query FindRowsQuery) (int, error) {
+	indexes, err := t.matchRows(query)
+	if err != nil {
+		return 0, err
+	}
+	return t.DeleteRows(indexes...)
+}
+
+func (t *bDataMatrix) DeleteRows(indexes ...int) (int, error) {
+	drop := make(map[int]struct{}, len(indexes))
+	for _, index := range indexes {
+		if index < 0 || index >= t.LenRows() {
+			return 0, fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index)
+		}
+		drop[index] = struct{}{}
+	}
+	if len(drop) == 0 {
+		return 0, nil
+	}
+	return t.removeRows(func(i int) bool {
+		_, ok := drop[i]
+		return ok
+	}), nil
+}
+
+func (t *bDataMatrix) UpdateWhere(query FindRowsQuery, key string, value string) (int, error) {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	indexes, err := t.matchRows(query)
+	if err != nil {
+		return 0, err
+	}
+	// Check everything up front so that either every matching row is updated or none is.
+	if err = t.checkSchemaValue(t.header[idx], value); err != nil {
+		return 0, err
+	}
+	if t.isKeyColumn(t.header[idx]) && len(indexes) > 1 {
+		return 0, fmt.Errorf("%w: %d rows would hold the same key", ErrDuplicateKey, len(indexes))
+	}
+	for _, i := range indexes {
+		if err = t.UpdateRowColumn(i, key, value); err != nil {
+			return 0, fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return len(indexes), nil
+}
+
+func (t *bDataMatrix) UpdateWhereFunc(query FindRowsQuery, fn func(row Row) ([]string, error)) (int, error) {
+	indexes, err := t.matchRows(query)
+	if err != nil {
+		return 0, err
+	}
+	if len(indexes) == 0 {
+		return 0, nil
+	}
+	// Every new row is checked before any is applied, and the primary key is checked once all are
+	// applied, so that rows can swap keys with each other.
+	rows := make([][]string, len(indexes))
+	for n, i := range indexes {
+		values, err := fn(t.row(i))
+		if err != nil {
+			return 0, fmt.Errorf("row %d: %w", i, err)
+		}
+		if len(values) != t.LenColumns() {
+			return 0, fmt.Errorf("row %d: row length (%d) does not match header length (%d)", i, len(values), t.LenColumns())
+		}
+		if err = t.checkSchema(values); err != nil {
+			return 0, fmt.Errorf("row %d: %w", i, err)
+		}
+		rows[n] = values
+	}
+	for n, i := range indexes {
+		rows[n], t.rows[i] = t.rows[i], rows[n]
+	}
+	keyIndex := t.keyIndex
+	if err = t.reindexKeys(); err != nil {
+		for n, i := range indexes {
+			t.rows[i] = rows[n]
+		}
+		t.keyIndex = keyIndex
+		return 0, err
+	}
+	if t.nulls != nil {
+		for _, i := range indexes {
+			t.nulls[i] = nil
+		}
+	}
+	return len(indexes), nil
+}
@@ -0,0 +1,92 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDeleteWhere tests DeleteWhere and DeleteRows.
+func TestDeleteWhere(t *testing.T) {
+	matrix := newNumberedMatrix(6)
+	matrix.SetPrimaryKey("ID")
+
+	n, err := matrix.DeleteWhere(FindRowsQuery{Column: "Group", Operator: OperatorEquals, Value: "b"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 deleted rows, got %d", n)
+	}
+	assertColumn(t, matrix, "ID", "0", "2", "4")
+	if row, _ := matrix.GetByKey("4"); row[0] != "4" {
+		t.Fatalf("expected primary key index to follow the deletion, got %v", row)
+	}
+	if n, _ = matrix.DeleteWhere(FindRowsQuery{Column: "Group", Operator: OperatorEquals, Value: "b"}); n != 0 {
+		t.Fatalf("expected no deleted rows, got %d", n)
+	}
+
+	if n, err = matrix.DeleteRows(2, 0, 2); err != nil || n != 2 {
+		t.Fatalf("expected 2 deleted rows, got %d, %v", n, err)
+	}
+	assertColumn(t, matrix, "ID", "2")
+	if _, err = matrix.DeleteRows(0, 5); !errors.Is(err, ErrRowIndexOutOfRange) {
+		t.Fatalf("expected row index out of range error, got %v", err)
+	}
+	if matrix.LenRows() != 1 {
+		t.Fatalf("expected no row to be deleted on error, got %d rows", matrix.LenRows())
+	}
+}
+
+// TestUpdateWhere tests UpdateWhere and UpdateWhereFunc.
+func TestUpdateWhere(t *testing.T) {
+	matrix := newNumberedMatrix(4)
+	matrix.SetPrimaryKey("ID")
+	query := FindRowsQuery{Column: "Group", Operator: OperatorEquals, Value: "a"}
+
+	n, err := matrix.UpdateWhere(query, "Group", "c")
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 updated rows, got %d, %v", n, err)
+	}
+	assertColumn(t, matrix, "Group", "c", "b", "c", "b")
+
+	query.Value = "b"
+	if _, err = matrix.UpdateWhere(query, "ID", "9"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+
+	n, err = matrix.UpdateWhereFunc(query, func(row Row) ([]string, error) {
+		values := row.Values()
+		values[0] = values[0] + "0"
+		return values, nil
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 updated rows, got %d, %v", n, err)
+	}
+	assertColumn(t, matrix, "ID", "0", "10", "2", "30")
+
+	_, err = matrix.UpdateWhereFunc(query, func(row Row) ([]string, error) {
+		return []string{"0", "b"}, nil
+	})
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	assertColumn(t, matrix, "ID", "0", "10", "2", "30")
+	if row, err := matrix.GetByKey("10"); err != nil || row[0] != "10" {
+		t.Fatalf("expected the key index to be restored, got %v, %v", row, err)
+	}
+
+	// Rows can swap their keys.
+	swapped := map[string]string{"10": "30", "30": "10"}
+	n, err = matrix.UpdateWhereFunc(query, func(row Row) ([]string, error) {
+		values := row.Values()
+		values[0] = swapped[values[0]]
+		return values, nil
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 updated rows, got %d, %v", n, err)
+	}
+	assertColumn(t, matrix, "ID", "0", "30", "2", "10")
+	if row, err := matrix.GetByKey("10"); err != nil || row[0] != "10" {
+		t.Fatalf("expected the key index to follow the swap, got %v, %v", row, err)
+	}
+}
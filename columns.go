@@ -0,0 +1,155 @@
+package bdatamatrix
+
+import "fmt"
+
+func (t *bDataMatrix) RenameColumn(old, new string) error {
+	return t.RenameColumns(map[string]string{old: new})
+}
+
+func (t *bDataMatrix) RenameColumns(renames map[string]string) error {
+	newHeader := append([]string(nil), t.header...)
+	renamed := make(map[string]string, len(renames))
+	for old, new := range renames {
+		idx, exists := t.headerIndex[old]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, old)
+		}
+		if _, ok := renamed[t.header[idx]]; ok {
+			return fmt.Errorf("%w: %s is renamed twice", ErrDuplicateHeader, t.header[idx])
+		}
+		renamed[t.header[idx]] = new
+		newHeader[idx] = new
+	}
+	seen := make(map[string]struct{}, len(newHeader))
+	for _, h := range newHeader {
+		if _, ok := seen[h]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateHeader, h)
+		}
+		if _, ok := t.aliases[h]; ok {
+			return fmt.Errorf("%w: %s is an alias", ErrDuplicateHeader, h)
+		}
+		seen[h] = struct{}{}
+	}
+
+	t.header = newHeader
+	if t.schema != nil {
+		for i, c := range t.schema.Columns {
+			if new, ok := renamed[c.Column]; ok {
+				t.schema.Columns[i].Column = new
+			}
+		}
+	}
+	for i, key := range t.primaryKey {
+		if new, ok := renamed[key]; ok {
+			t.primaryKey[i] = new
+		}
+	}
+	for alias, key := range t.aliases {
+		if new, ok := renamed[key]; ok {
+			t.aliases[alias] = new
+		}
+	}
+	return t.calculateHeaderIndex()
+}
+
+func (t *bDataMatrix) ReorderColumns(keys ...string) error {
+	order := make([]int, 0, t.LenColumns())
+	listed := make([]bool, t.LenColumns())
+	for _, key := range keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+		if listed[idx] {
+			return fmt.Errorf("%w: %s", ErrDuplicateHeader, key)
+		}
+		listed[idx] = true
+		order = append(order, idx)
+	}
+	for idx := range t.header {
+		if !listed[idx] {
+			order = append(order, idx)
+		}
+	}
+	t.reorderColumns(order)
+	return nil
+}
+
+func (t *bDataMatrix) MoveColumn(key string, position int) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	if position < 0 || position >= t.LenColumns() {
+		return fmt.Errorf("%w: %d", ErrColumnIndexOutOfRange, position)
+	}
+	order := make([]int, 0, t.LenColumns())
+	for i := range t.header {
+		if i != idx {
+			order = append(order, i)
+		}
+	}
+	order = append(order[:position], append([]int{idx}, order[position:]...)...)
+	t.reorderColumns(order)
+	return nil
+}
+
+func (t *bDataMatrix) AddColumnAlias(alias, key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	if _, exists = t.headerIndex[alias]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateHeader, alias)
+	}
+	if t.aliases == nil {
+		t.aliases = make(map[string]string)
+	}
+	t.aliases[alias] = t.header[idx]
+	t.headerIndex[alias] = idx
+	return nil
+}
+
+func (t *bDataMatrix) RemoveColumnAlias(alias string) error {
+	if _, exists := t.aliases[alias]; !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, alias)
+	}
+	delete(t.aliases, alias)
+	delete(t.headerIndex, alias)
+	return nil
+}
+
+func (t *bDataMatrix) ColumnAliases() map[string]string {
+	aliases := make(map[string]string, len(t.aliases))
+	for alias, key := range t.aliases {
+		aliases[alias] = key
+	}
+	return aliases
+}
+
+// reorderColumns rearranges the columns so that the column at order[i] moves to position i.
+func (t *bDataMatrix) reorderColumns(order []int) {
+	header := make([]string, len(order))
+	for i, idx := range order {
+		header[i] = t.header[idx]
+	}
+	for r, row := range t.rows {
+		newRow := make([]string, len(order))
+		for i, idx := range order {
+			newRow[i] = row[idx]
+		}
+		t.rows[r] = newRow
+	}
+	for r, flags := range t.nulls {
+		if flags == nil {
+			continue
+		}
+		newFlags := make([]bool, len(order))
+		for i, idx := range order {
+			newFlags[i] = flags[idx]
+		}
+		t.nulls[r] = newFlags
+	}
+	t.header = header
+	_ = t.calculateHeaderIndex()
+}
@@ -0,0 +1,211 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRenameColumn tests RenameColumn and RenameColumns.
+func TestRenameColumn(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice", "30"}}, "ID", "Name", "Age")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "ID", Kind: KindInt}}})
+	matrix.SetPrimaryKey("ID")
+	if err := matrix.RenameColumn("ID", "UserID"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "UserID", "Name", "Age")
+	if pk := matrix.PrimaryKey(); pk[0] != "UserID" {
+		t.Fatalf("expected primary key to follow the rename, got %v", pk)
+	}
+	if schema, _ := matrix.Schema(); schema.Columns[0].Column != "UserID" {
+		t.Fatalf("expected schema to follow the rename, got %v", schema)
+	}
+	if err := matrix.AddRow("x", "Bob", "25"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error, got %v", err)
+	}
+	if _, err := matrix.GetByKey("1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := matrix.GetColumn("ID"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+
+	// Test swapping names.
+	if err := matrix.RenameColumns(map[string]string{"Name": "Age", "Age": "Name"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "UserID", "Age", "Name")
+	if v, _ := matrix.GetRowData(0, "Name"); v != "30" {
+		t.Fatalf("expected swapped column to keep its values, got %s", v)
+	}
+
+	// Test errors.
+	if err := matrix.RenameColumn("Gender", "Sex"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.RenameColumn("Name", "Age"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	assertHeader(t, matrix, "UserID", "Age", "Name")
+}
+
+// TestReorderColumns tests ReorderColumns and MoveColumn.
+func TestReorderColumns(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice", "30", "EU"}}, "ID", "Name", "Age", "Region")
+	matrix.SetNull(0, "Region")
+	if err := matrix.ReorderColumns("Region", "Name"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "Region", "Name", "ID", "Age")
+	row, _ := matrix.GetRow(0)
+	if row[1] != "Alice" || row[3] != "30" {
+		t.Fatalf("expected values to follow their columns, got %v", row)
+	}
+	if null, _ := matrix.IsNull(0, "Region"); !null {
+		t.Fatal("expected nulls to follow their columns")
+	}
+
+	if err := matrix.MoveColumn("Region", 3); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "Name", "ID", "Age", "Region")
+	matrix.MoveColumn("ID", 0)
+	assertHeader(t, matrix, "ID", "Name", "Age", "Region")
+
+	if err := matrix.ReorderColumns("Name", "Name"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if err := matrix.ReorderColumns("Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.MoveColumn("ID", 4); !errors.Is(err, ErrColumnIndexOutOfRange) {
+		t.Fatalf("expected column index out of range error, got %v", err)
+	}
+	if err := matrix.MoveColumn("Gender", 0); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
+
+// TestColumnAlias tests AddColumnAlias, RemoveColumnAlias and ColumnAliases.
+func TestColumnAlias(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+	matrix.RenameColumn("Name", "FullName")
+	if err := matrix.AddColumnAlias("Name", "FullName"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if v, err := matrix.GetRowData(0, "Name"); err != nil || v != "Alice" {
+		t.Fatalf("expected alias to resolve, got %v, %v", v, err)
+	}
+	assertHeader(t, matrix, "ID", "FullName")
+
+	// Aliases follow renames, reorders and copies.
+	matrix.RenameColumn("FullName", "DisplayName")
+	matrix.MoveColumn("DisplayName", 0)
+	if v, _ := matrix.Copy().GetRowData(0, "Name"); v != "Alice" {
+		t.Fatalf("expected alias to follow the column, got %v", v)
+	}
+	if aliases := matrix.ColumnAliases(); aliases["Name"] != "DisplayName" {
+		t.Fatalf("unexpected aliases %v", aliases)
+	}
+
+	if err := matrix.AddColumnAlias("ID", "DisplayName"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if err := matrix.AddColumn("Name"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if err := matrix.AddColumnAlias("X", "Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+
+	if err := matrix.RemoveColumnAlias("Name"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := matrix.GetColumn("Name"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.RemoveColumnAlias("Name"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+
+	// Aliases of deleted columns are dropped.
+	matrix.AddColumnAlias("Name", "DisplayName")
+	matrix.DeleteColumn("DisplayName")
+	if len(matrix.ColumnAliases()) != 0 {
+		t.Fatalf("expected no aliases, got %v", matrix.ColumnAliases())
+	}
+}
+
+// TestColumnAliasKeyAndSchema tests that UpdateRowColumn, SetNull, DeleteColumn and schema checks
+// treat an alias as the column it names.
+func TestColumnAliasKeyAndSchema(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice", "30"}, {"2", "Bob", "25"}}, "ID", "Name", "Age")
+	matrix.SetPrimaryKey("ID")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Age", Kind: KindInt}}})
+	matrix.AddColumnAlias("Key", "ID")
+	matrix.AddColumnAlias("Years", "Age")
+
+	if err := matrix.UpdateRowColumn(1, "Key", "1"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if err := matrix.UpdateRowColumn(1, "Key", "3"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if row, err := matrix.GetByKey("3"); err != nil || row[1] != "Bob" {
+		t.Fatalf("expected the key index to follow the update, got %v, %v", row, err)
+	}
+	if _, err := matrix.GetByKey("2"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+
+	if err := matrix.UpdateRowColumn(0, "Years", "old"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error, got %v", err)
+	}
+	if err := matrix.SetNull(0, "Key"); !errors.Is(err, ErrPrimaryKeyColumn) {
+		t.Fatalf("expected primary key column error, got %v", err)
+	}
+	if err := matrix.DeleteColumn("Key"); !errors.Is(err, ErrPrimaryKeyColumn) {
+		t.Fatalf("expected primary key column error, got %v", err)
+	}
+	assertHeader(t, matrix, "ID", "Name", "Age")
+}
+
+// TestColumnAliasDeclarations tests that a primary key and a schema declared through aliases name
+// the header columns.
+func TestColumnAliasDeclarations(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice", "30"}, {"2", "Bob", "25"}}, "ID", "Name", "Age")
+	matrix.AddColumnAlias("Key", "ID")
+	matrix.AddColumnAlias("Years", "Age")
+	if err := matrix.SetPrimaryKey("Key"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Years", Kind: KindInt}}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pk := matrix.PrimaryKey(); len(pk) != 1 || pk[0] != "ID" {
+		t.Fatalf("expected primary key [ID], got %v", pk)
+	}
+	if schema, _ := matrix.Schema(); schema.Columns[0].Column != "Age" {
+		t.Fatalf("expected schema column Age, got %s", schema.Columns[0].Column)
+	}
+	if err := matrix.SetPrimaryKey("ID", "Key"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+
+	if err := matrix.UpdateRowColumn(0, "Age", "abc"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error, got %v", err)
+	}
+	if err := matrix.DeleteColumn("ID"); !errors.Is(err, ErrPrimaryKeyColumn) {
+		t.Fatalf("expected primary key column error, got %v", err)
+	}
+
+	if err := matrix.RenameColumn("ID", "Code"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pk := matrix.PrimaryKey(); len(pk) != 1 || pk[0] != "Code" {
+		t.Fatalf("expected the primary key to follow the rename, got %v", pk)
+	}
+	if row, err := matrix.GetByKey("2"); err != nil || row[1] != "Bob" {
+		t.Fatalf("expected row of Bob, got %v, %v", row, err)
+	}
+}
@@ -0,0 +1,28 @@
+package bdatamatrix
+
+import "fmt"
+
+func (t *bDataMatrix) AddComputedColumn(key, expr string) error {
+	e, err := compileExpr(expr, t)
+	if err != nil {
+		return err
+	}
+	return t.AddComputedColumnFunc(key, func(row Row) (string, error) {
+		return e(row.values)
+	})
+}
+
+func (t *bDataMatrix) AddComputedColumnFunc(key string, fn func(row Row) (string, error)) error {
+	if _, exists := t.headerIndex[key]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateHeader, key)
+	}
+	values := make([]string, t.LenRows())
+	for i := range t.rows {
+		v, err := fn(t.row(i))
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		values[i] = v
+	}
+	return t.AddColumn(key, values...)
+}
@@ -0,0 +1,129 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAddComputedColumn tests AddComputedColumn.
+func TestAddComputedColumn(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"Alice", "Smith", "2.5", "4", "", "15/03/2024"},
+		{"Bob", "Jones", "10", "3", "VIP", "01/12/2023"},
+	}, "First", "Last", "Price", "Qty", "Tier", "Joined At")
+
+	tests := []struct {
+		key      string
+		expr     string
+		expected []string
+	}{
+		{"Total", "Price * Qty", []string{"10", "30"}},
+		{"FullName", `First + " " + Last`, []string{"Alice Smith", "Bob Jones"}},
+		{"Discounted", "(Price - 0.5) * Qty / 2", []string{"4", "14.25"}},
+		{"Remainder", "Qty % 2", []string{"0", "1"}},
+		{"Expensive", "Price * Qty >= 30 && Tier != ''", []string{"false", "true"}},
+		{"Either", "Price > 5 || Qty == 4", []string{"true", "true"}},
+		{"NotVIP", "!(Tier == 'VIP')", []string{"true", "false"}},
+		{"Label", "if(Price > 5, 'high', 'low')", []string{"low", "high"}},
+		{"TierOrNone", "coalesce(Tier, 'none')", []string{"none", "VIP"}},
+		{"Shout", "upper(First) + lower(' X')", []string{"ALICE x", "BOB x"}},
+		{"Trimmed", "trim('  a ') + concat(len(First), '!')", []string{"a5!", "a3!"}},
+		{"Initials", "substr(First, 0, 1) + substr(Last, 0, 1)", []string{"AS", "BJ"}},
+		{"Tail", "substr(Last, 3)", []string{"th", "es"}},
+		{"Negative", "-Qty", []string{"-4", "-3"}},
+		{"Joined", "format_date(parse_date([Joined At], '02/01/2006'), '2006-01-02')", []string{"2024-03-15", "2023-12-01"}},
+	}
+	for _, tt := range tests {
+		if err := matrix.AddComputedColumn(tt.key, tt.expr); err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.expr, err)
+		}
+		assertColumn(t, matrix, tt.key, tt.expected...)
+	}
+}
+
+// TestAddComputedColumnNumbers tests integer and float arithmetic, and when + adds or concatenates.
+func TestAddComputedColumnNumbers(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"0812", "007", "9007199254740993", "0.1", "10", "5", "Tea"},
+	}, "Phone", "Code", "Big", "Rate", "Price", "Tax", "Item")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{
+		{Column: "Big", Kind: KindInt},
+		{Column: "Rate", Kind: KindFloat},
+	}})
+
+	tests := []struct {
+		key      string
+		expr     string
+		expected string
+	}{
+		{"Dialed", "concat(Phone, Code)", "0812007"},
+		{"Padded", "concat(Code, 1)", "0071"},
+		{"Sum", "Code + 1", "8"},
+		{"Gross", "Price + Tax", "15"},
+		{"Double", "Price * 2 + Tax", "25"},
+		{"Label", "Item + Price", "Tea10"},
+		{"Spaced", "Price + ' ' + Tax", "10 5"},
+		{"Next", "Big + 1", "9007199254740994"},
+		{"Product", "Big * 1", "9007199254740993"},
+		{"Half", "7 / 2", "3.5"},
+		{"Whole", "8 / 2", "4"},
+		{"Overflow", "9223372036854775807 + 1", "9223372036854780000"},
+		{"Noise", "0.1 + 0.2", "0.3"},
+		{"Rated", "Rate + 0.2", "0.3"},
+		{"Scaled", "Rate * 3", "0.3"},
+		{"Fallback", "coalesce(Big, 0) + 1", "9007199254740994"},
+		{"Length", "len(Phone) + 1", "5"},
+	}
+	for _, tt := range tests {
+		if err := matrix.AddComputedColumn(tt.key, tt.expr); err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.expr, err)
+		}
+		assertColumn(t, matrix, tt.key, tt.expected)
+	}
+}
+
+// TestAddComputedColumnErrors tests AddComputedColumn errors.
+func TestAddComputedColumnErrors(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"Alice", "0"}}, "Name", "Qty")
+	invalid := []string{"", "Qty +", "(Qty", "'open", "[Qty", "Qty $ 2", "nope(Qty)", "upper()", "1.2.3", "Qty Qty"}
+	for _, expr := range invalid {
+		if err := matrix.AddComputedColumn("X", expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Fatalf("%q: expected invalid expression error, got %v", expr, err)
+		}
+	}
+	if err := matrix.AddComputedColumn("X", "Age + 1"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	failing := []string{"1 / Qty", "1 % Qty", "Name * 2", "-Name", "substr(Name, 'a')", "parse_date(Name, '2006')"}
+	for _, expr := range failing {
+		if err := matrix.AddComputedColumn("X", expr); err == nil {
+			t.Fatalf("%q: expected evaluation error, got nil", expr)
+		}
+	}
+	if err := matrix.AddComputedColumn("Name", "1"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if matrix.LenColumns() != 2 {
+		t.Fatalf("expected no column to be added, got %v", matrix.Header())
+	}
+}
+
+// TestAddComputedColumnFunc tests AddComputedColumnFunc.
+func TestAddComputedColumnFunc(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"Alice"}, {"Bob"}}, "Name")
+	err := matrix.AddComputedColumnFunc("Greeting", func(row Row) (string, error) {
+		name, err := row.Get("Name")
+		return "Hello " + name, err
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Greeting", "Hello Alice", "Hello Bob")
+
+	err = matrix.AddComputedColumnFunc("Failing", func(row Row) (string, error) {
+		return "", errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,190 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConcatMode defines how the columns of concatenated matrices are aligned.
+type ConcatMode int
+
+const (
+	// ConcatStrict requires every matrix to have exactly the same header.
+	ConcatStrict ConcatMode = iota + 1
+	// ConcatByName aligns columns by name. The header is the union of all headers in order of first
+	// appearance, and cells of columns missing from a matrix are null.
+	ConcatByName
+	// ConcatIntersect keeps only the columns present in every matrix, in the order of the first one.
+	ConcatIntersect
+	// ConcatPositional aligns columns by position and keeps the header of the first matrix. Every
+	// matrix must have the same number of columns.
+	ConcatPositional
+)
+
+func (m ConcatMode) String() string {
+	v, ok := map[ConcatMode]string{
+		ConcatStrict:     "strict",
+		ConcatByName:     "by_name",
+		ConcatIntersect:  "intersect",
+		ConcatPositional: "positional",
+	}[m]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// ConcatOptions specifies how matrices are concatenated by ConcatWith.
+type ConcatOptions struct {
+	// Mode is the column alignment mode. The zero value means ConcatStrict.
+	Mode ConcatMode
+	// SourceColumn is the name of a column appended to the result to record which matrix each row
+	// came from. No column is added when it is empty.
+	SourceColumn string
+	// Sources holds the values written to SourceColumn for each matrix, starting with the receiver.
+	// When nil, the position of the matrix is used: "0" for the receiver, "1" for the first other
+	// matrix, and so on.
+	Sources []string
+}
+
+func (t *bDataMatrix) Concat(mode ConcatMode, others ...BDataMatrix) (BDataMatrix, error) {
+	return t.ConcatWith(ConcatOptions{Mode: mode}, others...)
+}
+
+func (t *bDataMatrix) ConcatWith(opts ConcatOptions, others ...BDataMatrix) (BDataMatrix, error) {
+	inputs := append([]BDataMatrix{t}, others...)
+	if opts.Sources != nil && len(opts.Sources) != len(inputs) {
+		return nil, fmt.Errorf("sources length (%d) does not match the number of matrices (%d)", len(opts.Sources), len(inputs))
+	}
+	header, err := concatHeader(opts.Mode, inputs)
+	if err != nil {
+		return nil, err
+	}
+	width := len(header)
+	if opts.SourceColumn != "" {
+		header = append(header, opts.SourceColumn)
+	}
+	result := &bDataMatrix{header: header}
+	if err = result.calculateHeaderIndex(); err != nil {
+		return nil, err
+	}
+
+	for n, m := range inputs {
+		// cols maps every result column to the column of m it is read from, or -1 when m lacks it.
+		mHeader := m.Header()
+		mIndex := columnIndex(mHeader)
+		cols := make([]int, width)
+		for j, key := range header[:width] {
+			if opts.Mode == ConcatPositional {
+				cols[j] = j
+			} else if idx, exists := mIndex[key]; exists {
+				cols[j] = idx
+			} else {
+				cols[j] = -1
+			}
+		}
+		for i, values := range m.Rows() {
+			row := make([]string, len(header))
+			var flags []bool
+			for j, idx := range cols {
+				null := true
+				if idx >= 0 {
+					row[j] = values[idx]
+					null, _ = m.IsNull(i, mHeader[idx])
+				}
+				if null {
+					if flags == nil {
+						flags = make([]bool, len(header))
+					}
+					flags[j] = true
+				}
+			}
+			if opts.SourceColumn != "" {
+				row[width] = strconv.Itoa(n)
+				if opts.Sources != nil {
+					row[width] = opts.Sources[n]
+				}
+			}
+			if flags != nil && result.nulls == nil {
+				result.nulls = make([][]bool, len(result.rows))
+			}
+			if result.nulls != nil {
+				result.nulls = append(result.nulls, flags)
+			}
+			result.rows = append(result.rows, row)
+		}
+	}
+	return result, nil
+}
+
+// concatHeader returns the header of the concatenation of the given matrices.
+func concatHeader(mode ConcatMode, inputs []BDataMatrix) ([]string, error) {
+	first := inputs[0].Header()
+	switch mode {
+	case 0, ConcatStrict:
+		for n, m := range inputs[1:] {
+			if !equalHeader(first, m.Header()) {
+				return nil, fmt.Errorf("%w: matrix %d has header [%s], expected [%s]",
+					ErrHeaderMismatch, n+1, strings.Join(m.Header(), ", "), strings.Join(first, ", "))
+			}
+		}
+		return append([]string(nil), first...), nil
+	case ConcatByName:
+		var header []string
+		seen := make(map[string]struct{})
+		for _, m := range inputs {
+			for _, key := range m.Header() {
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					header = append(header, key)
+				}
+			}
+		}
+		return header, nil
+	case ConcatIntersect:
+		indexes := make([]map[string]int, len(inputs)-1)
+		for n, m := range inputs[1:] {
+			indexes[n] = columnIndex(m.Header())
+		}
+		var header []string
+		for _, key := range first {
+			common := true
+			for _, index := range indexes {
+				if _, exists := index[key]; !exists {
+					common = false
+					break
+				}
+			}
+			if common {
+				header = append(header, key)
+			}
+		}
+		if len(header) == 0 {
+			return nil, fmt.Errorf("%w: no common columns", ErrHeaderMismatch)
+		}
+		return header, nil
+	case ConcatPositional:
+		for n, m := range inputs[1:] {
+			if m.LenColumns() != len(first) {
+				return nil, fmt.Errorf("%w: matrix %d has %d columns, expected %d",
+					ErrHeaderMismatch, n+1, m.LenColumns(), len(first))
+			}
+		}
+		return append([]string(nil), first...), nil
+	default:
+		return nil, fmt.Errorf("unknown concat mode: %v", mode)
+	}
+}
+
+func equalHeader(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestConcat tests Concat with every mode.
+func TestConcat(t *testing.T) {
+	jan, _ := NewWithData([][]string{{"1", "Alice", "30"}}, "ID", "Name", "Age")
+	feb, _ := NewWithData([][]string{{"2", "Bob", "25"}}, "ID", "Name", "Age")
+	mar, _ := NewWithData([][]string{{"Carol", "3", "carol@example.com"}}, "Name", "ID", "Email")
+
+	strict, err := jan.Concat(ConcatStrict, feb)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, strict, "Name", "Alice", "Bob")
+	if _, err = jan.Concat(ConcatStrict, mar); !errors.Is(err, ErrHeaderMismatch) {
+		t.Fatalf("expected header mismatch error, got %v", err)
+	}
+
+	byName, err := jan.Concat(ConcatByName, feb, mar)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, byName, "ID", "Name", "Age", "Email")
+	assertColumn(t, byName, "ID", "1", "2", "3")
+	assertColumn(t, byName, "Age", "30", "25", "")
+	if null, _ := byName.IsNull(2, "Age"); !null {
+		t.Fatalf("expected missing cell to be null")
+	}
+	if null, _ := byName.IsNull(0, "Name"); null {
+		t.Fatalf("expected present cell not to be null")
+	}
+
+	intersect, err := jan.Concat(ConcatIntersect, mar)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, intersect, "ID", "Name")
+	assertColumn(t, intersect, "Name", "Alice", "Carol")
+
+	positional, err := jan.Concat(ConcatPositional, mar)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, positional, "ID", "Name", "Age")
+	assertColumn(t, positional, "ID", "1", "Carol")
+	short, _ := New("ID")
+	if _, err = jan.Concat(ConcatPositional, short); !errors.Is(err, ErrHeaderMismatch) {
+		t.Fatalf("expected header mismatch error, got %v", err)
+	}
+}
+
+// TestConcatWith tests ConcatWith with a source column.
+func TestConcatWith(t *testing.T) {
+	jan, _ := NewWithData([][]string{{"1"}, {"2"}}, "ID")
+	feb, _ := NewWithData([][]string{{"3"}}, "ID")
+
+	result, err := jan.ConcatWith(ConcatOptions{SourceColumn: "Source"}, feb)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, result, "Source", "0", "0", "1")
+
+	result, err = jan.ConcatWith(ConcatOptions{SourceColumn: "Month", Sources: []string{"jan", "feb"}}, feb)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, result, "Month", "jan", "jan", "feb")
+
+	if _, err = jan.ConcatWith(ConcatOptions{SourceColumn: "ID"}, feb); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if _, err = jan.ConcatWith(ConcatOptions{SourceColumn: "Month", Sources: []string{"jan"}}, feb); err == nil {
+		t.Fatalf("expected error for missing source names")
+	}
+	if jan.LenRows() != 2 {
+		t.Fatalf("expected receiver to be left unchanged, got %d rows", jan.LenRows())
+	}
+}
@@ -0,0 +1,523 @@
+package bdatamatrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxBodyBytes is the maximum size of a request body when CRUDOptions.MaxBodyBytes is zero.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Store loads and saves the matrix edited through the handler of NewCRUDHandler.
+//
+// The handler never modifies a loaded matrix: changes are made to a copy, which is then saved.
+type Store interface {
+	// Load returns the current matrix and its version.
+	Load(ctx context.Context) (BDataMatrix, int64, error)
+	// Save replaces the matrix of the given version, storing matrix as version+1. It returns an
+	// error wrapping ErrVersionConflict when the stored version is no longer version.
+	Save(ctx context.Context, matrix BDataMatrix, version int64) error
+}
+
+// MemoryStore is a Store keeping the matrix in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	matrix  BDataMatrix
+	version int64
+}
+
+// NewMemoryStore creates a MemoryStore holding the matrix at version 1. The matrix must not be
+// modified afterwards.
+func NewMemoryStore(matrix BDataMatrix) *MemoryStore {
+	return &MemoryStore{matrix: matrix, version: 1}
+}
+
+func (s *MemoryStore) Load(context.Context) (BDataMatrix, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matrix, s.version, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, matrix BDataMatrix, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if version != s.version {
+		return fmt.Errorf("%w: saving over version %d, current version is %d", ErrVersionConflict, version, s.version)
+	}
+	s.matrix = matrix
+	s.version++
+	return nil
+}
+
+// CRUDOptions configures the handler returned by NewCRUDHandler.
+type CRUDOptions struct {
+	// HTTP configures how GET /rows serves the matrix.
+	HTTP HTTPOptions
+	// RequireVersion indicates whether changes without an If-Match header are rejected with 428
+	// Precondition Required.
+	RequireVersion bool
+	// Rules are checked after every change against the rows it affects, or against every row when
+	// a column is added or deleted. Changes breaking a rule are rejected with 422 Unprocessable
+	// Entity.
+	Rules []Rule
+	// MaxBodyBytes is the maximum size of a request body. The zero value means DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+}
+
+// NewCRUDHandler returns a handler reading and editing the matrix of store. Request paths are
+// relative to the handler, so mount it with http.StripPrefix when it does not serve the root:
+//   - GET /rows serves the rows like NewHTTPHandler, with its query parameters but without an
+//     ETag, since ETags computed from the content cannot be used in If-Match.
+//   - POST /rows adds a row from a JSON object, answering 201 Created with the row and its
+//     location.
+//   - GET /rows/{i} serves the row at index i as a JSON object.
+//   - PUT /rows/{i} replaces the row at index i with a JSON object.
+//   - PATCH /rows/{i} updates the columns of the row at index i present in a JSON object.
+//   - DELETE /rows/{i} deletes the row at index i.
+//   - GET /columns serves the header as a JSON array.
+//   - POST /columns adds a column from a JSON object such as {"name": "Email", "values": ["a@b.c"]}.
+//   - DELETE /columns/{name} deletes a column.
+//
+// JSON values are stored as their text, and null values and columns missing from POST and PUT
+// objects become null cells. A matrix that was not created by this package is copied like
+// NewHTTPHandler does, and saved as a copy. Changes are checked against the schema, the primary key and
+// opts.Rules, then saved through the store.
+//
+// Every response carries the version of the matrix in the X-Version header. Changes are applied
+// only when their If-Match header, if any, holds the current version, and are rejected with 412
+// Precondition Failed otherwise, so that concurrent editors cannot overwrite each other. Clients
+// send the X-Version of the response they read the matrix from in If-Match, such as If-Match: 7.
+//
+// Example usage:
+//
+//	store := NewMemoryStore(matrix)
+//	http.Handle("/countries/", http.StripPrefix("/countries", NewCRUDHandler(store, CRUDOptions{})))
+//	// PATCH /countries/rows/3 with If-Match: 7 and {"Name": "Indonesia"}
+func NewCRUDHandler(store Store, opts CRUDOptions) http.Handler {
+	if opts.HTTP.DefaultFormat == "" {
+		opts.HTTP.DefaultFormat = "json"
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &crudHandler{store: store, opts: opts}
+}
+
+type crudHandler struct {
+	store Store
+	opts  CRUDOptions
+	// mu serializes the changes made through the handler. Changes made elsewhere are detected by
+	// the version check of the store.
+	mu sync.Mutex
+}
+
+// statusError is an error answered with a specific HTTP status.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+func badRequest(err error) error {
+	return &statusError{status: http.StatusBadRequest, err: err}
+}
+
+func (h *crudHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments, err := pathSegments(r.URL.EscapedPath())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case len(segments) == 1 && segments[0] == "rows":
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			h.read(w, r, func(t *bDataMatrix) {
+				serveMatrix(w, r, t, h.opts.HTTP, false)
+			})
+		case http.MethodPost:
+			h.change(w, r, http.StatusCreated, lastRow, h.addRow)
+		default:
+			methodNotAllowed(w, "GET, HEAD, POST")
+		}
+	case len(segments) == 2 && segments[0] == "rows":
+		index, err := strconv.Atoi(segments[1])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid row index: %s", segments[1]), http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			h.read(w, r, func(t *bDataMatrix) {
+				if index < 0 || index >= t.LenRows() {
+					writeError(w, fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index))
+					return
+				}
+				writeJSON(w, r, http.StatusOK, t.rowMapWithNulls(index))
+			})
+		case http.MethodPut:
+			h.change(w, r, http.StatusOK, rowAt(index), func(t *bDataMatrix, body []byte) (any, error) {
+				return h.replaceRow(t, index, body)
+			})
+		case http.MethodPatch:
+			h.change(w, r, http.StatusOK, rowAt(index), func(t *bDataMatrix, body []byte) (any, error) {
+				return h.updateRow(t, index, body)
+			})
+		case http.MethodDelete:
+			h.change(w, r, http.StatusNoContent, noRows, func(t *bDataMatrix, _ []byte) (any, error) {
+				return nil, t.DeleteRow(index)
+			})
+		default:
+			methodNotAllowed(w, "GET, HEAD, PUT, PATCH, DELETE")
+		}
+	case len(segments) == 1 && segments[0] == "columns":
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			h.read(w, r, func(t *bDataMatrix) {
+				writeJSON(w, r, http.StatusOK, t.header)
+			})
+		case http.MethodPost:
+			h.change(w, r, http.StatusCreated, allRows, h.addColumn)
+		default:
+			methodNotAllowed(w, "GET, HEAD, POST")
+		}
+	case len(segments) == 2 && segments[0] == "columns":
+		if r.Method != http.MethodDelete {
+			methodNotAllowed(w, "DELETE")
+			return
+		}
+		h.change(w, r, http.StatusNoContent, allRows, func(t *bDataMatrix, _ []byte) (any, error) {
+			return nil, t.DeleteColumn(segments[1])
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// read loads the matrix and passes it to serve.
+func (h *crudHandler) read(w http.ResponseWriter, r *http.Request, serve func(t *bDataMatrix)) {
+	matrix, version, err := h.store.Load(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Version", strconv.FormatInt(version, 10))
+	t, err := httpMatrix(matrix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serve(t)
+}
+
+// rowScope returns the indexes of the rows affected by a change, once it is applied to t.
+type rowScope func(t *bDataMatrix) []int
+
+func lastRow(t *bDataMatrix) []int { return []int{t.LenRows() - 1} }
+func noRows(*bDataMatrix) []int    { return nil }
+func allRows(t *bDataMatrix) []int { return t.allIndexes() }
+
+func rowAt(index int) rowScope {
+	return func(*bDataMatrix) []int { return []int{index} }
+}
+
+// change applies fn to a copy of the matrix and saves it, answering with status and the value
+// returned by fn as JSON. The rules are checked against the rows of scope.
+func (h *crudHandler) change(w http.ResponseWriter, r *http.Request, status int, scope rowScope, fn func(t *bDataMatrix, body []byte) (any, error)) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	matrix, version, err := h.store.Load(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Version", strconv.FormatInt(version, 10))
+	if status, err := checkVersion(r.Header.Get("If-Match"), version, h.opts.RequireVersion); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	stored, err := httpMatrix(matrix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	t, ok := stored.Copy().(*bDataMatrix)
+	if !ok {
+		http.Error(w, "copying the matrix failed", http.StatusInternalServerError)
+		return
+	}
+	result, err := fn(t, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(h.opts.Rules) > 0 {
+		report, err := t.validateRows(scope(t), h.opts.Rules...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err = report.Err(); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	if err = h.store.Save(r.Context(), t, version); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Version", strconv.FormatInt(version+1, 10))
+	if created, ok := result.(createdRow); ok {
+		w.Header().Set("Location", rowLocation(r, created.index))
+		result = created.row
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, r, status, result)
+}
+
+// rowLocation returns the location of the row at index, as seen by the client. The request URI is
+// used rather than the path, which http.StripPrefix may have shortened.
+func rowLocation(r *http.Request, index int) string {
+	p := r.URL.Path
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		p = u.Path
+	}
+	return strings.TrimSuffix(p, "/") + "/" + strconv.Itoa(index)
+}
+
+// createdRow is the result of adding a row, holding its index for the Location header.
+type createdRow struct {
+	index int
+	row   map[string]any
+}
+
+func (h *crudHandler) addRow(t *bDataMatrix, body []byte) (any, error) {
+	values, nulls, err := rowFromJSON(t, body)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.AddRow(values...); err != nil {
+		return nil, err
+	}
+	index := t.LenRows() - 1
+	for _, j := range nulls {
+		t.markNull(index, j, true)
+	}
+	return createdRow{index: index, row: t.rowMapWithNulls(index)}, nil
+}
+
+func (h *crudHandler) replaceRow(t *bDataMatrix, index int, body []byte) (any, error) {
+	values, nulls, err := rowFromJSON(t, body)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.UpdateRow(index, values...); err != nil {
+		return nil, err
+	}
+	for _, j := range nulls {
+		t.markNull(index, j, true)
+	}
+	return t.rowMapWithNulls(index), nil
+}
+
+func (h *crudHandler) updateRow(t *bDataMatrix, index int, body []byte) (any, error) {
+	obj, err := parseJSONObject(body)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	if _, err = bodyColumns(t, obj); err != nil {
+		return nil, err
+	}
+	for _, key := range obj.keys {
+		if value, ok := obj.values[key]; ok {
+			err = t.UpdateRowColumn(index, key, value)
+		} else {
+			err = t.SetNull(index, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.rowMapWithNulls(index), nil
+}
+
+func (h *crudHandler) addColumn(t *bDataMatrix, body []byte) (any, error) {
+	var column struct {
+		Name   string   `json:"name"`
+		Values []string `json:"values"`
+	}
+	if err := json.Unmarshal(body, &column); err != nil {
+		return nil, badRequest(err)
+	}
+	if column.Name == "" {
+		return nil, badRequest(errors.New("column name is required"))
+	}
+	if len(column.Values) > t.LenRows() {
+		return nil, badRequest(fmt.Errorf("%d values given for %d rows", len(column.Values), t.LenRows()))
+	}
+	// Rows after the given values get an empty value.
+	values := make([]string, t.LenRows())
+	copy(values, column.Values)
+	if err := t.AddColumn(column.Name, values...); err != nil {
+		return nil, err
+	}
+	return t.header, nil
+}
+
+// rowFromJSON returns the values of a row held by a JSON object, and the indexes of the columns
+// that are null or missing from it.
+func rowFromJSON(t *bDataMatrix, body []byte) ([]string, []int, error) {
+	obj, err := parseJSONObject(body)
+	if err != nil {
+		return nil, nil, badRequest(err)
+	}
+	idxs, err := bodyColumns(t, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]string, t.LenColumns())
+	set := make([]bool, t.LenColumns())
+	for n, key := range obj.keys {
+		if value, ok := obj.values[key]; ok {
+			values[idxs[n]] = value
+			set[idxs[n]] = true
+		}
+	}
+	var nulls []int
+	for j, key := range t.header {
+		if set[j] {
+			continue
+		}
+		if t.isKeyColumn(key) {
+			return nil, nil, badRequest(fmt.Errorf("%w: %s cannot be null", ErrPrimaryKeyColumn, key))
+		}
+		nulls = append(nulls, j)
+	}
+	return values, nulls, nil
+}
+
+// bodyColumns returns the index of the column named by every key of obj. It rejects keys that are
+// not columns, and keys naming the same column, such as a column and one of its aliases.
+func bodyColumns(t *bDataMatrix, obj jsonObject) ([]int, error) {
+	idxs := make([]int, len(obj.keys))
+	seen := make(map[int]string, len(obj.keys))
+	for n, key := range obj.keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return nil, badRequest(fmt.Errorf("%w: %s", ErrColumnNotFound, key))
+		}
+		if other, ok := seen[idx]; ok {
+			return nil, badRequest(fmt.Errorf("%w: %s and %s name the same column", ErrDuplicateHeader, other, key))
+		}
+		seen[idx] = key
+		idxs[n] = idx
+	}
+	return idxs, nil
+}
+
+// checkVersion checks the If-Match header against the current version. It returns the HTTP status
+// to answer with when the change must be rejected.
+func checkVersion(ifMatch string, version int64, required bool) (int, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" {
+		if required {
+			return http.StatusPreconditionRequired, errors.New("an If-Match header with the version is required")
+		}
+		return 0, nil
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+		if tag == "*" || tag == strconv.FormatInt(version, 10) {
+			return 0, nil
+		}
+	}
+	return http.StatusPreconditionFailed, fmt.Errorf("%w: current version is %d", ErrVersionConflict, version)
+}
+
+// errorStatus returns the HTTP status answering a failed change.
+func errorStatus(err error) int {
+	var se *statusError
+	switch {
+	case errors.As(err, &se):
+		return se.status
+	case errors.Is(err, ErrRowIndexOutOfRange), errors.Is(err, ErrColumnNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrSchemaMismatch), errors.Is(err, ErrValidationFailed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrDuplicateKey), errors.Is(err, ErrDuplicateHeader),
+		errors.Is(err, ErrPrimaryKeyColumn), errors.Is(err, ErrDeleteLastColumn):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), errorStatus(err))
+}
+
+// writeJSON answers with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(data)
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+// pathSegments splits an escaped path into its unescaped segments, so column names may hold
+// slashes when escaped.
+func pathSegments(escaped string) ([]string, error) {
+	trimmed := strings.Trim(escaped, "/")
+	if trimmed == "" {
+		return nil, nil
+	}
+	segments := strings.Split(trimmed, "/")
+	for i, s := range segments {
+		v, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = v
+	}
+	return segments, nil
+}
@@ -0,0 +1,305 @@
+package bdatamatrix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// request sends a request with a body to handler and returns the response.
+func request(handler http.Handler, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestCRUDHandlerRows tests the row endpoints.
+func TestCRUDHandlerRows(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"ID", "Indonesia", "275"},
+		{"JP", "Japan", "125"},
+	}, "Code", "Name", "Population")
+	matrix.SetPrimaryKey("Code")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Population", Kind: KindInt}}})
+	store := NewMemoryStore(matrix)
+	handler := http.StripPrefix("/countries", NewCRUDHandler(store, CRUDOptions{}))
+
+	w := request(handler, http.MethodPost, "/countries/rows", `{"Code": "FR", "Name": "France", "Population": 68}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Location"); got != "/countries/rows/2" {
+		t.Fatalf("unexpected location %s", got)
+	}
+	if got := w.Header().Get("X-Version"); got != "2" {
+		t.Fatalf("expected version 2, got %s", got)
+	}
+	if got, expected := w.Body.String(), `{"Code":"FR","Name":"France","Population":"68"}`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	w = request(handler, http.MethodPatch, "/countries/rows/1", `{"Name": "Nippon", "Population": null}`, map[string]string{"If-Match": `"2"`})
+	if got, expected := w.Body.String(), `{"Code":"JP","Name":"Nippon","Population":null}`; w.Code != http.StatusOK || got != expected {
+		t.Fatalf("expected %s, got %d: %s", expected, w.Code, got)
+	}
+
+	w = request(handler, http.MethodPut, "/countries/rows/0", `{"Code": "ID", "Name": "Republic of Indonesia"}`, nil)
+	if got, expected := w.Body.String(), `{"Code":"ID","Name":"Republic of Indonesia","Population":null}`; w.Code != http.StatusOK || got != expected {
+		t.Fatalf("expected %s, got %d: %s", expected, w.Code, got)
+	}
+
+	w = request(handler, http.MethodDelete, "/countries/rows/2", "", map[string]string{"If-Match": "4"})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body)
+	}
+
+	w = request(handler, http.MethodGet, "/countries/rows?format=csv", "", nil)
+	if got, expected := w.Body.String(), "Code,Name,Population\nID,Republic of Indonesia,\nJP,Nippon,\n"; got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if got := w.Header().Get("X-Version"); got != "5" {
+		t.Fatalf("expected version 5, got %s", got)
+	}
+	w = request(handler, http.MethodGet, "/countries/rows/1", "", nil)
+	if got, expected := w.Body.String(), `{"Code":"JP","Name":"Nippon","Population":null}`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPost, "/countries/rows", `{"Code": "JP", "Name": "Japan"}`, http.StatusConflict},
+		{http.MethodPost, "/countries/rows", `{"Code": "KR", "Population": "many"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/countries/rows", `{"Code": "KR", "Capital": "Seoul"}`, http.StatusBadRequest},
+		{http.MethodPost, "/countries/rows", `{"Name": "Korea"}`, http.StatusBadRequest},
+		{http.MethodPost, "/countries/rows", `not json`, http.StatusBadRequest},
+		{http.MethodPatch, "/countries/rows/9", `{"Name": "x"}`, http.StatusNotFound},
+		{http.MethodPatch, "/countries/rows/0", `{"Code": null}`, http.StatusConflict},
+		{http.MethodGet, "/countries/rows/9", "", http.StatusNotFound},
+		{http.MethodDelete, "/countries/rows/x", "", http.StatusBadRequest},
+		{http.MethodPost, "/countries/rows/0", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/countries/other", "", http.StatusNotFound},
+	} {
+		if w = request(handler, tc.method, tc.target, tc.body, nil); w.Code != tc.status {
+			t.Fatalf("%s %s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.body, tc.status, w.Code, w.Body)
+		}
+	}
+	if _, version, _ := store.Load(context.Background()); version != 5 {
+		t.Fatalf("expected failed changes to keep version 5, got %d", version)
+	}
+}
+
+// TestCRUDHandlerColumns tests the column endpoints.
+func TestCRUDHandlerColumns(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"ID", "Indonesia", "275"},
+		{"JP", "Japan", "125"},
+	}, "Code", "Name", "Population")
+	matrix.SetPrimaryKey("Code")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Population", Kind: KindInt}}})
+	store := NewMemoryStore(matrix)
+	handler := NewCRUDHandler(store, CRUDOptions{})
+
+	w := request(handler, http.MethodPost, "/columns", `{"name": "Capital/City", "values": ["Jakarta"]}`, nil)
+	if got, expected := w.Body.String(), `["Code","Name","Population","Capital/City"]`; w.Code != http.StatusCreated || got != expected {
+		t.Fatalf("expected %s, got %d: %s", expected, w.Code, got)
+	}
+	w = request(handler, http.MethodGet, "/rows/1", "", nil)
+	if got, expected := w.Body.String(), `{"Capital/City":"","Code":"JP","Name":"Japan","Population":"125"}`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if w = request(handler, http.MethodDelete, "/columns/Capital%2FCity", "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body)
+	}
+	w = request(handler, http.MethodGet, "/columns", "", nil)
+	if got, expected := w.Body.String(), `["Code","Name","Population"]`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPost, "/columns", `{"name": "Name"}`, http.StatusConflict},
+		{http.MethodPost, "/columns", `{"values": ["a"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/columns", `{"name": "X", "values": ["a", "b", "c"]}`, http.StatusBadRequest},
+		{http.MethodDelete, "/columns/Code", "", http.StatusConflict},
+		{http.MethodDelete, "/columns/Missing", "", http.StatusNotFound},
+		{http.MethodGet, "/columns/Name", "", http.StatusMethodNotAllowed},
+	} {
+		if w = request(handler, tc.method, tc.target, tc.body, nil); w.Code != tc.status {
+			t.Fatalf("%s %s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.body, tc.status, w.Code, w.Body)
+		}
+	}
+}
+
+// TestCRUDHandlerAliases tests that column aliases are accepted in bodies and paths, and that a
+// body naming a column twice is rejected.
+func TestCRUDHandlerAliases(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"ID", "Indonesia"}, {"JP", "Japan"}}, "Code", "Name")
+	matrix.SetPrimaryKey("Code")
+	matrix.AddColumn("Capital", "Jakarta", "Tokyo")
+	matrix.AddColumnAlias("ISO", "Code")
+	matrix.AddColumnAlias("City", "Capital")
+	store := NewMemoryStore(matrix)
+	handler := NewCRUDHandler(store, CRUDOptions{})
+
+	w := request(handler, http.MethodPatch, "/rows/1", `{"ISO": "JPN"}`, nil)
+	if got, expected := w.Body.String(), `{"Capital":"Tokyo","Code":"JPN","Name":"Japan"}`; w.Code != http.StatusOK || got != expected {
+		t.Fatalf("expected %s, got %d: %s", expected, w.Code, got)
+	}
+	if w = request(handler, http.MethodGet, "/rows/1", "", nil); !strings.Contains(w.Body.String(), `"Code":"JPN"`) {
+		t.Fatalf("expected the key to be updated through its alias, got %s", w.Body)
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodPatch, "/rows/0", `{"ISO": null}`, http.StatusConflict},
+		{http.MethodPatch, "/rows/0", `{"ISO": "JPN"}`, http.StatusConflict},
+		{http.MethodPatch, "/rows/0", `{"Capital": "a", "City": "b"}`, http.StatusBadRequest},
+		{http.MethodPost, "/rows", `{"Code": "KR", "ISO": "KOR", "Name": "Korea"}`, http.StatusBadRequest},
+		{http.MethodPut, "/rows/0", `{"ISO": "ID", "Code": "ID"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/columns/ISO", "", http.StatusConflict},
+	} {
+		if w = request(handler, tc.method, tc.target, tc.body, nil); w.Code != tc.status {
+			t.Fatalf("%s %s %s: expected status %d, got %d: %s", tc.method, tc.target, tc.body, tc.status, w.Code, w.Body)
+		}
+	}
+
+	if w = request(handler, http.MethodDelete, "/columns/City", "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body)
+	}
+	w = request(handler, http.MethodGet, "/columns", "", nil)
+	if got, expected := w.Body.String(), `["Code","Name"]`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+// TestCRUDHandlerVersions tests optimistic concurrency and rules.
+func TestCRUDHandlerVersions(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"ID", "Indonesia", "275"},
+		{"JP", "Japan", "125"},
+	}, "Code", "Name", "Population")
+	matrix.SetPrimaryKey("Code")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Population", Kind: KindInt}}})
+	store := NewMemoryStore(matrix)
+	handler := NewCRUDHandler(store, CRUDOptions{RequireVersion: true, Rules: []Rule{Required("Name")}})
+
+	w := request(handler, http.MethodPatch, "/rows/0", `{"Name": "RI"}`, nil)
+	if w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("expected status 428, got %d", w.Code)
+	}
+	w = request(handler, http.MethodPatch, "/rows/0", `{"Name": "RI"}`, map[string]string{"If-Match": "1"})
+	if w.Code != http.StatusOK || w.Header().Get("X-Version") != "2" {
+		t.Fatalf("expected version 2, got %d %s", w.Code, w.Header().Get("X-Version"))
+	}
+	// A second editor still holding version 1 must not overwrite the change.
+	w = request(handler, http.MethodPatch, "/rows/0", `{"Name": "Indonesia"}`, map[string]string{"If-Match": "1"})
+	if w.Code != http.StatusPreconditionFailed || w.Header().Get("X-Version") != "2" {
+		t.Fatalf("expected status 412 with version 2, got %d %s", w.Code, w.Header().Get("X-Version"))
+	}
+	w = request(handler, http.MethodPatch, "/rows/0", `{"Name": ""}`, map[string]string{"If-Match": "*"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body)
+	}
+
+	// A client reads the rows, then changes a row with the version it read.
+	w = request(handler, http.MethodGet, "/rows?format=csv", "", nil)
+	if w.Header().Get("ETag") != "" {
+		t.Fatalf("expected no ETag, got %s", w.Header().Get("ETag"))
+	}
+	read := w.Header().Get("X-Version")
+	w = request(handler, http.MethodPatch, "/rows/1", `{"Name": "Nippon"}`, map[string]string{"If-Match": read})
+	if w.Code != http.StatusOK || w.Header().Get("X-Version") != "3" {
+		t.Fatalf("expected version 3, got %d %s: %s", w.Code, w.Header().Get("X-Version"), w.Body)
+	}
+	w = request(handler, http.MethodPatch, "/rows/1", `{"Name": "Japan"}`, map[string]string{"If-Match": read})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected status 412 for the stale version, got %d", w.Code)
+	}
+
+	limited := NewCRUDHandler(store, CRUDOptions{MaxBodyBytes: 8})
+	if w = request(limited, http.MethodPatch, "/rows/0", `{"Name": "Indonesia"}`, nil); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", w.Code)
+	}
+	r := httptest.NewRequest(http.MethodPatch, "/rows/0", iotest.ErrReader(errors.New("connection reset")))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a failed read, got %d", w.Code)
+	}
+
+	stored, _, _ := store.Load(context.Background())
+	if got := stored.Rows()[0]; !reflect.DeepEqual(got, []string{"ID", "RI", "275"}) {
+		t.Fatalf("unexpected row %v", got)
+	}
+	if err := store.Save(context.Background(), stored, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+// TestCRUDHandlerOtherMatrix tests that other implementations of BDataMatrix are copied with their
+// primary key and schema, and that a nil matrix is answered with 500.
+func TestCRUDHandlerOtherMatrix(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "10"}}, "ID", "Qty")
+	matrix.SetPrimaryKey("ID")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Qty", Kind: KindInt}}})
+	store := NewMemoryStore(struct{ BDataMatrix }{matrix})
+	handler := NewCRUDHandler(store, CRUDOptions{})
+	if w := request(handler, http.MethodPost, "/rows", `{"ID": "2", "Qty": "3"}`, nil); w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body)
+	}
+	if w := request(handler, http.MethodPost, "/rows", `{"ID": "1", "Qty": "3"}`, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for a duplicate key, got %d: %s", w.Code, w.Body)
+	}
+	if w := request(handler, http.MethodPost, "/rows", `{"ID": "3", "Qty": "x"}`, nil); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for a schema mismatch, got %d: %s", w.Code, w.Body)
+	}
+	if saved, _, _ := store.Load(context.Background()); saved.LenRows() != 2 {
+		t.Fatalf("expected 2 saved rows, got %d", saved.LenRows())
+	}
+
+	handler = NewCRUDHandler(NewMemoryStore(nil), CRUDOptions{})
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if w := request(handler, method, "/rows", `{"ID": "2"}`, nil); w.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected status 500, got %d: %s", method, w.Code, w.Body)
+		}
+	}
+}
+
+// TestCRUDHandlerRules tests that rules are checked against the rows a change affects.
+func TestCRUDHandlerRules(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "a"}, {"2", ""}, {"3", "c"}}, "ID", "Code")
+	handler := NewCRUDHandler(NewMemoryStore(matrix), CRUDOptions{Rules: []Rule{Required("Code"), Unique("Code")}})
+
+	// The empty code of row 1 does not block changes to other rows.
+	if w := request(handler, http.MethodPatch, "/rows/0", `{"Code": "b"}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+	}
+	if w := request(handler, http.MethodDelete, "/rows/2", "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body)
+	}
+	for _, change := range []struct{ method, target, body string }{
+		{http.MethodPatch, "/rows/1", `{"Code": ""}`},
+		{http.MethodPatch, "/rows/1", `{"Code": "b"}`},
+		{http.MethodPost, "/rows", `{"ID": "4", "Code": "b"}`},
+		{http.MethodPost, "/columns", `{"name": "Note"}`},
+	} {
+		if w := request(handler, change.method, change.target, change.body, nil); w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s %s %s: expected status 422, got %d: %s", change.method, change.target, change.body, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CSVOptions specifies the dialect of the CSV export of ToCSVWith. The zero value gives the same
+// output as ToCSV(true).
+type CSVOptions struct {
+	// Delimiter is the field delimiter. The zero value means ','.
+	Delimiter rune
+	// UseCRLF indicates whether lines end with \r\n instead of \n.
+	UseCRLF bool
+	// BOM indicates whether the output starts with a UTF-8 byte order mark, which some spreadsheet
+	// applications need to detect the encoding.
+	BOM bool
+	// AlwaysQuote indicates whether every field is quoted, instead of only the fields that need it.
+	AlwaysQuote bool
+	// EscapeFormulas indicates whether cells starting with '=', '+', '-', '@', a tab or a carriage
+	// return are prefixed with a single quote, so spreadsheet applications do not run them as
+	// formulas.
+	EscapeFormulas bool
+	// OmitHeader indicates whether the header is left out.
+	OmitHeader bool
+}
+
+// CSV dialect presets for ToCSVWith. Copy a preset to change its options.
+var (
+	// CSVExcel is the dialect of Excel: comma delimiters, CRLF line endings and a byte order mark.
+	CSVExcel = CSVOptions{Delimiter: ',', UseCRLF: true, BOM: true}
+	// CSVExcelEU is the dialect of Excel in locales using a decimal comma: semicolon delimiters,
+	// CRLF line endings and a byte order mark.
+	CSVExcelEU = CSVOptions{Delimiter: ';', UseCRLF: true, BOM: true}
+	// CSVRFC4180 is the dialect of RFC 4180: comma delimiters and CRLF line endings.
+	CSVRFC4180 = CSVOptions{Delimiter: ',', UseCRLF: true}
+	// CSVUnix is the common dialect of Unix tools: comma delimiters, LF line endings and every field
+	// quoted.
+	CSVUnix = CSVOptions{Delimiter: ',', AlwaysQuote: true}
+)
+
+var errInvalidDelimiter = errors.New("csv: invalid field delimiter")
+
+func (t *bDataMatrix) ToCSVWith(opts CSVOptions) StreamingOutput {
+	return &outputData{stream: t.snapshot().csvStream(opts)}
+}
+
+func (t *bDataMatrix) WriteCSVWith(w io.Writer, opts CSVOptions) error {
+	return runStream(t.csvStream(opts), w)
+}
+
+// csvStream exports the matrix in CSV format with the given dialect.
+func (t *bDataMatrix) csvStream(opts CSVOptions) stream {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	return func(w io.Writer) func() error {
+		var sb strings.Builder
+		write := func() error {
+			_, err := io.WriteString(w, sb.String())
+			sb.Reset()
+			return err
+		}
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				if !validDelimiter(opts.Delimiter) {
+					return errInvalidDelimiter
+				}
+				if opts.BOM {
+					sb.WriteString("\ufeff")
+				}
+				if !opts.OmitHeader {
+					opts.writeRecord(&sb, t.header)
+				}
+				return nil
+			},
+			item: func(i int) error {
+				opts.writeRecord(&sb, t.rows[i])
+				return nil
+			},
+			flush: write,
+		}.next()
+	}
+}
+
+// writeRecord writes a CSV record, following the quoting rules of encoding/csv.
+func (opts CSVOptions) writeRecord(sb *strings.Builder, record []string) {
+	newline := "\n"
+	if opts.UseCRLF {
+		newline = "\r\n"
+	}
+	for n, field := range record {
+		if n > 0 {
+			sb.WriteRune(opts.Delimiter)
+		}
+		if opts.EscapeFormulas && field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+			field = "'" + field
+		}
+		if !opts.AlwaysQuote && !opts.fieldNeedsQuotes(field) {
+			sb.WriteString(field)
+			continue
+		}
+		sb.WriteByte('"')
+		for i := 0; i < len(field); i++ {
+			switch c := field[i]; c {
+			case '"':
+				sb.WriteString(`""`)
+			case '\r':
+				if !opts.UseCRLF {
+					sb.WriteByte(c)
+				}
+			case '\n':
+				sb.WriteString(newline)
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		sb.WriteByte('"')
+	}
+	sb.WriteString(newline)
+}
+
+func (opts CSVOptions) fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, opts.Delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// quoteCustom quotes a value of a custom export when it holds the separator, a quote or a line
+// break, doubling the quotes inside it.
+func quoteCustom(value, separator string) string {
+	if (separator == "" || !strings.Contains(value, separator)) && !strings.ContainsAny(value, "\"\r\n") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
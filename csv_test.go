@@ -0,0 +1,70 @@
+package bdatamatrix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+// TestToCSVMatchesEncodingCSV tests that the default dialect quotes like encoding/csv.
+func TestToCSVMatchesEncodingCSV(t *testing.T) {
+	rows := [][]string{
+		{"", " leading", "a,b", `say "hi"`},
+		{"line\nbreak", "cr\rlf", `\.`, "tab\tinside"},
+		{"\xff", "ünïcode", "-1", "=SUM(A1)"},
+	}
+	matrix, _ := NewWithData(rows, "A", "B", "C", "D")
+	for _, crlf := range []bool{false, true} {
+		for _, delimiter := range []rune{',', ';', '\t', '¦'} {
+			var buf bytes.Buffer
+			writer := csv.NewWriter(&buf)
+			writer.Comma, writer.UseCRLF = delimiter, crlf
+			writer.WriteAll(matrix.Data(true))
+			got := matrix.ToCSVWith(CSVOptions{Delimiter: delimiter, UseCRLF: crlf}).String()
+			if got != buf.String() {
+				t.Fatalf("delimiter %q, CRLF %v: expected %q, got %q", delimiter, crlf, buf.String(), got)
+			}
+		}
+	}
+}
+
+// TestToCSVWith tests the CSV dialect presets and options.
+func TestToCSVWith(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "3,5", "=HYPERLINK(\"x\")"}}, "ID", "Price", "Note")
+
+	tests := []struct {
+		name     string
+		opts     CSVOptions
+		expected string
+	}{
+		{"excel", CSVExcel, "\ufeffID,Price,Note\r\n1,\"3,5\",\"=HYPERLINK(\"\"x\"\")\"\r\n"},
+		{"excel eu", CSVExcelEU, "\ufeffID;Price;Note\r\n1;3,5;\"=HYPERLINK(\"\"x\"\")\"\r\n"},
+		{"rfc4180", CSVRFC4180, "ID,Price,Note\r\n1,\"3,5\",\"=HYPERLINK(\"\"x\"\")\"\r\n"},
+		{"unix", CSVUnix, "\"ID\",\"Price\",\"Note\"\n\"1\",\"3,5\",\"=HYPERLINK(\"\"x\"\")\"\n"},
+		{"formulas", CSVOptions{EscapeFormulas: true, OmitHeader: true}, "1,\"3,5\",\"'=HYPERLINK(\"\"x\"\")\"\n"},
+	}
+	for _, tt := range tests {
+		if got := matrix.ToCSVWith(tt.opts).String(); got != tt.expected {
+			t.Fatalf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := matrix.WriteCSVWith(&buf, CSVOptions{Delimiter: '\n'}); err == nil {
+		t.Fatalf("expected an error for an invalid delimiter")
+	}
+}
+
+// TestToCustomQuoting tests that separators inside values cannot corrupt custom output.
+func TestToCustomQuoting(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"a | b", `say "hi"`, "plain"}}, "A", "B", "C")
+	got := matrix.ToCustom(false, " | ").String()
+	expected := `"a | b" | "say ""hi""" | plain`
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if strings.Count(matrix.ToCustom(true, " | ").String(), "\n") != 1 {
+		t.Fatalf("expected one line per row")
+	}
+}
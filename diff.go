@@ -0,0 +1,332 @@
+package bdatamatrix
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RowChange describes a row added or removed between two versions of a matrix.
+type RowChange struct {
+	// Key holds the values of the key columns of the row.
+	Key []string `json:"key"`
+	// Values holds every value of the row by column name.
+	Values map[string]string `json:"values"`
+}
+
+// CellChange describes a single cell whose value changed between two versions of a matrix.
+type CellChange struct {
+	// Key holds the values of the key columns of the row.
+	Key []string `json:"key"`
+	// Column is the header name of the changed column.
+	Column string `json:"column"`
+	// Old is the value in the old version.
+	Old string `json:"old"`
+	// New is the value in the new version.
+	New string `json:"new"`
+}
+
+// Changeset describes the differences between two versions of a matrix, as returned by Diff.
+type Changeset struct {
+	// Key holds the names of the columns used to match rows.
+	Key []string `json:"key"`
+	// ColumnsAdded holds the names of the columns only present in the new version.
+	ColumnsAdded []string `json:"columns_added,omitempty"`
+	// ColumnsRemoved holds the names of the columns only present in the old version.
+	ColumnsRemoved []string `json:"columns_removed,omitempty"`
+	// RowsAdded holds the rows only present in the new version.
+	RowsAdded []RowChange `json:"rows_added,omitempty"`
+	// RowsRemoved holds the rows only present in the old version.
+	RowsRemoved []RowChange `json:"rows_removed,omitempty"`
+	// CellsChanged holds the cells of common rows and columns whose value changed.
+	CellsChanged []CellChange `json:"cells_changed,omitempty"`
+}
+
+// Empty reports whether the changeset holds no change.
+func (c Changeset) Empty() bool {
+	return len(c.ColumnsAdded) == 0 && len(c.ColumnsRemoved) == 0 &&
+		len(c.RowsAdded) == 0 && len(c.RowsRemoved) == 0 && len(c.CellsChanged) == 0
+}
+
+// ToJSON exports the changeset to JSON format.
+//
+// Parameters:
+//   - compact: Want return json data with format minified (compact) or not.
+//
+// Returns:
+//   - If param true, return json data with format minified (compact). If param false, return json data with format pretty-printed.
+func (c Changeset) ToJSON(compact bool) StreamingOutput {
+	var output []byte
+	var err error
+	if compact {
+		output, err = json.Marshal(c)
+	} else {
+		output, err = json.MarshalIndent(c, "", "  ")
+	}
+	if err != nil {
+		return &outputData{stream: errorStream(err)}
+	}
+	return &outputData{stream: bytesStream(output)}
+}
+
+// String renders the changeset as a readable report, one change per line.
+func (c Changeset) String() string {
+	if c.Empty() {
+		return "no changes"
+	}
+	var lines []string
+	for _, col := range c.ColumnsAdded {
+		lines = append(lines, fmt.Sprintf("+ column %s", col))
+	}
+	for _, col := range c.ColumnsRemoved {
+		lines = append(lines, fmt.Sprintf("- column %s", col))
+	}
+	for _, r := range c.RowsAdded {
+		lines = append(lines, fmt.Sprintf("+ row %s", c.describeKey(r.Key)))
+	}
+	for _, r := range c.RowsRemoved {
+		lines = append(lines, fmt.Sprintf("- row %s", c.describeKey(r.Key)))
+	}
+	for _, cc := range c.CellsChanged {
+		lines = append(lines, fmt.Sprintf("~ row %s, column %s: '%s' -> '%s'", c.describeKey(cc.Key), cc.Column, cc.Old, cc.New))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c Changeset) describeKey(key []string) string {
+	parts := make([]string, len(key))
+	for i, v := range key {
+		parts[i] = fmt.Sprintf("%s=%s", c.Key[i], v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Diff compares two versions of a matrix and returns the changes needed to turn old into updated.
+//
+// Rows are matched by the values of keyCols. When no key columns are given, the primary key of
+// old is used, and when old has no primary key every common column is used, in which case
+// changed rows are reported as removed and added. Without key columns, repeated rows are matched
+// by count: surplus copies in old are reported as removed and surplus copies in updated as added.
+//
+// Example usage:
+//
+//	changes, err := Diff(yesterday, today, "ID")
+//	if err != nil {
+//	    // handle error
+//	}
+//	fmt.Println(changes)
+func Diff(old, updated BDataMatrix, keyCols ...string) (Changeset, error) {
+	newIndex := columnIndex(updated.Header())
+	oldIndex := columnIndex(old.Header())
+	var cs Changeset
+	var common []string
+	for _, h := range old.Header() {
+		if _, ok := newIndex[h]; ok {
+			common = append(common, h)
+		} else {
+			cs.ColumnsRemoved = append(cs.ColumnsRemoved, h)
+		}
+	}
+	for _, h := range updated.Header() {
+		if _, ok := oldIndex[h]; !ok {
+			cs.ColumnsAdded = append(cs.ColumnsAdded, h)
+		}
+	}
+
+	if len(keyCols) == 0 {
+		keyCols = old.PrimaryKey()
+	}
+	unique := true
+	if len(keyCols) == 0 {
+		keyCols = common
+		unique = false
+	}
+	cs.Key = append([]string(nil), keyCols...)
+	oldRows, oldOrder, err := keyRows(old, keyCols, unique)
+	if err != nil {
+		return Changeset{}, err
+	}
+	newRows, newOrder, err := keyRows(updated, keyCols, unique)
+	if err != nil {
+		return Changeset{}, err
+	}
+
+	// Rows sharing a key are paired in order, leaving the surplus copies removed or added.
+	for _, k := range oldOrder {
+		for i, o := range oldRows[k] {
+			oldRow := old.Rows()[o]
+			if i >= len(newRows[k]) {
+				cs.RowsRemoved = append(cs.RowsRemoved, rowChange(old.Header(), oldRow, oldIndex, keyCols))
+				continue
+			}
+			cs.CellsChanged = append(cs.CellsChanged, diffRow(cs.ColumnsAdded, common, keyCols, oldRow, updated.Rows()[newRows[k][i]], oldIndex, newIndex)...)
+		}
+	}
+	for _, k := range newOrder {
+		for _, n := range newRows[k][min(len(oldRows[k]), len(newRows[k])):] {
+			cs.RowsAdded = append(cs.RowsAdded, rowChange(updated.Header(), updated.Rows()[n], newIndex, keyCols))
+		}
+	}
+	return cs, nil
+}
+
+// diffRow returns the cells that differ between two matched rows.
+func diffRow(added, common, keyCols, oldRow, newRow []string, oldIndex, newIndex map[string]int) []CellChange {
+	var changes []CellChange
+	for _, h := range common {
+		if o, n := oldRow[oldIndex[h]], newRow[newIndex[h]]; o != n {
+			changes = append(changes, CellChange{
+				Key: rowKeyValues(oldRow, oldIndex, keyCols), Column: h, Old: o, New: n,
+			})
+		}
+	}
+	// Added columns start empty when applied, so their values are reported as changes from empty.
+	for _, h := range added {
+		if n := newRow[newIndex[h]]; n != "" {
+			changes = append(changes, CellChange{
+				Key: rowKeyValues(oldRow, oldIndex, keyCols), Column: h, Old: "", New: n,
+			})
+		}
+	}
+	return changes
+}
+
+func (t *bDataMatrix) ApplyChangeset(changeset Changeset) error {
+	c := t.Copy().(*bDataMatrix)
+	if err := c.applyChangeset(changeset); err != nil {
+		return err
+	}
+	*t = *c
+	return nil
+}
+
+func (t *bDataMatrix) applyChangeset(cs Changeset) error {
+	// A key spanning every kept column identifies rows by their whole values, as Diff does without
+	// key columns, so repeated rows are allowed.
+	unique := len(cs.Key)+len(cs.ColumnsRemoved) != t.LenColumns()
+	if err := t.AddColumns(cs.ColumnsAdded...); err != nil {
+		return err
+	}
+	// A changeset built by hand may leave its key out, in which case rows are matched by the
+	// primary key.
+	if len(cs.Key) == 0 {
+		if len(t.primaryKey) == 0 {
+			return fmt.Errorf("%w: the changeset has no key to match rows by", ErrNoPrimaryKey)
+		}
+		cs.Key = t.primaryKey
+	}
+	index, _, err := keyRows(t, cs.Key, unique)
+	if err != nil {
+		return err
+	}
+	// Repeated rows are told apart the way Diff pairs them: cell changes go to the first copies and
+	// removals to the last ones.
+	changed := make(map[string]int)
+	removals := make(map[string]int)
+	locate := func(key []string, seen map[string]int, seenKey string, fromEnd bool) (int, error) {
+		rows := index[encodeKey(key)]
+		n := seen[seenKey]
+		if n >= len(rows) {
+			return 0, fmt.Errorf("%w: %s", ErrKeyNotFound, strings.Join(key, ", "))
+		}
+		if !unique {
+			seen[seenKey]++
+		}
+		if fromEnd {
+			return rows[len(rows)-1-n], nil
+		}
+		return rows[n], nil
+	}
+
+	for _, cc := range cs.CellsChanged {
+		i, err := locate(cc.Key, changed, encodeKey([]string{encodeKey(cc.Key), cc.Column}), false)
+		if err != nil {
+			return err
+		}
+		current, err := t.GetRowData(i, cc.Column)
+		if err != nil {
+			return err
+		}
+		if current != cc.Old {
+			return fmt.Errorf("%w: row %s, column %s holds '%s', expected '%s'",
+				ErrChangesetConflict, strings.Join(cc.Key, ", "), cc.Column, current, cc.Old)
+		}
+		if err = t.UpdateRowColumn(i, cc.Column, cc.New); err != nil {
+			return err
+		}
+	}
+	removed := make([]int, 0, len(cs.RowsRemoved))
+	for _, r := range cs.RowsRemoved {
+		i, err := locate(r.Key, removals, encodeKey(r.Key), true)
+		if err != nil {
+			return err
+		}
+		removed = append(removed, i)
+	}
+	if _, err = t.DeleteRows(removed...); err != nil {
+		return err
+	}
+	for _, r := range cs.RowsAdded {
+		values := make([]string, t.LenColumns())
+		for i, h := range t.header {
+			values[i] = r.Values[h]
+		}
+		if err = t.AddRow(values...); err != nil {
+			return err
+		}
+	}
+	for _, col := range cs.ColumnsRemoved {
+		if err = t.DeleteColumn(col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyRows maps the encoded key of every row of m to the indexes of the rows holding it. The distinct
+// keys are returned in order of first appearance. Unless unique is false, a key held by several rows
+// is an error.
+func keyRows(m BDataMatrix, keyCols []string, unique bool) (map[string][]int, []string, error) {
+	index := columnIndex(m.Header())
+	for _, key := range keyCols {
+		if _, exists := index[key]; !exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+	}
+	rows := make(map[string][]int, m.LenRows())
+	order := make([]string, 0, m.LenRows())
+	for i, row := range m.Rows() {
+		k := encodeKey(rowKeyValues(row, index, keyCols))
+		if _, exists := rows[k]; !exists {
+			order = append(order, k)
+		} else if unique {
+			return nil, nil, fmt.Errorf("%w: row %d", ErrDuplicateKey, i)
+		}
+		rows[k] = append(rows[k], i)
+	}
+	return rows, order, nil
+}
+
+func rowKeyValues(row []string, index map[string]int, keyCols []string) []string {
+	key := make([]string, len(keyCols))
+	for i, k := range keyCols {
+		key[i] = row[index[k]]
+	}
+	return key
+}
+
+func rowChange(header, row []string, index map[string]int, keyCols []string) RowChange {
+	values := make(map[string]string, len(header))
+	for i, h := range header {
+		values[h] = row[i]
+	}
+	return RowChange{Key: rowKeyValues(row, index, keyCols), Values: values}
+}
+
+func columnIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[h] = i
+	}
+	return index
+}
@@ -0,0 +1,162 @@
+package bdatamatrix
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDiff tests Diff.
+func TestDiff(t *testing.T) {
+	old, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "25"},
+		{"3", "Carol", "41"},
+	}, "ID", "Name", "Age")
+	updated, _ := NewWithData([][]string{
+		{"1", "Alicia", "alice@example.com"},
+		{"3", "Carol", "carol@example.com"},
+		{"4", "Dave", "dave@example.com"},
+	}, "ID", "Name", "Email")
+	cs, err := Diff(old, updated, "ID")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cs.ColumnsAdded) != 1 || cs.ColumnsAdded[0] != "Email" {
+		t.Fatalf("expected column Email added, got %v", cs.ColumnsAdded)
+	}
+	if len(cs.ColumnsRemoved) != 1 || cs.ColumnsRemoved[0] != "Age" {
+		t.Fatalf("expected column Age removed, got %v", cs.ColumnsRemoved)
+	}
+	if len(cs.RowsAdded) != 1 || cs.RowsAdded[0].Key[0] != "4" || cs.RowsAdded[0].Values["Name"] != "Dave" {
+		t.Fatalf("expected row 4 added, got %+v", cs.RowsAdded)
+	}
+	if len(cs.RowsRemoved) != 1 || cs.RowsRemoved[0].Key[0] != "2" {
+		t.Fatalf("expected row 2 removed, got %+v", cs.RowsRemoved)
+	}
+	if len(cs.CellsChanged) != 3 {
+		t.Fatalf("expected 3 changed cells, got %+v", cs.CellsChanged)
+	}
+	if cc := cs.CellsChanged[0]; cc.Key[0] != "1" || cc.Column != "Name" || cc.Old != "Alice" || cc.New != "Alicia" {
+		t.Fatalf("unexpected changed cell %+v", cc)
+	}
+
+	report := cs.String()
+	for _, line := range []string{"+ column Email", "- column Age", "+ row ID=4", "- row ID=2", "~ row ID=1, column Name: 'Alice' -> 'Alicia'"} {
+		if !strings.Contains(report, line) {
+			t.Fatalf("expected report to contain %q, got:\n%s", line, report)
+		}
+	}
+
+	var decoded Changeset
+	if err = json.Unmarshal(cs.ToJSON(true).Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+	if len(decoded.CellsChanged) != 3 || decoded.Key[0] != "ID" {
+		t.Fatalf("unexpected decoded changeset %+v", decoded)
+	}
+	if !strings.Contains(cs.ToJSON(false).String(), "\n") {
+		t.Fatal("expected pretty-printed JSON")
+	}
+
+	// Test default keys.
+	old.SetPrimaryKey("ID")
+	cs, _ = Diff(old, updated)
+	if len(cs.Key) != 1 || cs.Key[0] != "ID" {
+		t.Fatalf("expected primary key to be used, got %v", cs.Key)
+	}
+	cs, _ = Diff(updated, updated)
+	if !cs.Empty() || cs.String() != "no changes" {
+		t.Fatalf("expected no changes, got %s", cs)
+	}
+
+	// Test errors.
+	if _, err = Diff(old, updated, "Age"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	duplicated, _ := NewWithData([][]string{{"1", "A"}, {"1", "B"}}, "ID", "Name")
+	if _, err = Diff(duplicated, updated, "ID"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+}
+
+// TestApplyChangeset tests ApplyChangeset.
+func TestApplyChangeset(t *testing.T) {
+	old, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "25"},
+		{"3", "Carol", "41"},
+	}, "ID", "Name", "Age")
+	updated, _ := NewWithData([][]string{
+		{"1", "Alicia", "alice@example.com"},
+		{"3", "Carol", "carol@example.com"},
+		{"4", "Dave", "dave@example.com"},
+	}, "ID", "Name", "Email")
+	cs, _ := Diff(old, updated, "ID")
+	patched := old.Copy()
+	if err := patched.ApplyChangeset(cs); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if remaining, _ := Diff(patched, updated, "ID"); !remaining.Empty() {
+		t.Fatalf("expected patched matrix to equal new matrix, got:\n%s", remaining)
+	}
+
+	// Test conflicts leave the matrix untouched.
+	conflicting := old.Copy()
+	conflicting.UpdateRowColumn(0, "Name", "Alex")
+	if err := conflicting.ApplyChangeset(cs); !errors.Is(err, ErrChangesetConflict) {
+		t.Fatalf("expected changeset conflict error, got %v", err)
+	}
+	if conflicting.LenColumns() != 3 || conflicting.LenRows() != 3 {
+		t.Fatal("expected matrix to be untouched after a failed apply")
+	}
+
+	missing := old.Copy()
+	missing.DeleteRow(1)
+	if err := missing.ApplyChangeset(cs); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+
+	// A changeset without a key matches rows by the primary key.
+	cs.Key = nil
+	keyless := old.Copy()
+	if err := keyless.ApplyChangeset(cs); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expected no primary key error, got %v", err)
+	}
+	keyless.SetPrimaryKey("ID")
+	if err := keyless.ApplyChangeset(cs); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if remaining, _ := Diff(keyless, updated, "ID"); !remaining.Empty() {
+		t.Fatalf("expected patched matrix to equal the updated matrix, got:\n%s", remaining)
+	}
+}
+
+// TestDiffRepeatedRows tests that Diff without key columns matches repeated rows by count.
+func TestDiffRepeatedRows(t *testing.T) {
+	old, _ := NewWithData([][]string{{"a", "1"}, {"a", "1"}, {"a", "1"}, {"b", "2"}}, "Name", "Qty")
+	updated, _ := NewWithData([][]string{{"a", "1", "x"}, {"b", "2", "y"}, {"b", "2", "z"}, {"c", "3", ""}}, "Name", "Qty", "Tag")
+	cs, err := Diff(old, updated)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cs.RowsRemoved) != 2 || len(cs.RowsAdded) != 2 || len(cs.CellsChanged) != 2 {
+		t.Fatalf("expected 2 removed rows, 2 added rows and 2 changed cells, got:\n%s", cs)
+	}
+	if r := cs.RowsRemoved[0]; r.Values["Name"] != "a" {
+		t.Fatalf("expected a surplus copy of a to be removed, got %+v", r)
+	}
+	if r := cs.RowsAdded[0]; r.Values["Name"] != "b" || r.Values["Tag"] != "z" {
+		t.Fatalf("expected a surplus copy of b to be added, got %+v", r)
+	}
+
+	if err = old.ApplyChangeset(cs); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := [][]string{{"a", "1", "x"}, {"b", "2", "y"}, {"b", "2", "z"}, {"c", "3", ""}}
+	if got := old.Rows(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,129 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeepPolicy defines which rows of a group of duplicates are kept by DistinctWith.
+type KeepPolicy int
+
+const (
+	// KeepFirst keeps the first row of each group of duplicates.
+	KeepFirst KeepPolicy = iota + 1
+	// KeepLast keeps the last row of each group of duplicates.
+	KeepLast
+	// KeepNone drops every row that has a duplicate.
+	KeepNone
+)
+
+func (p KeepPolicy) String() string {
+	v, ok := map[KeepPolicy]string{
+		KeepFirst: "keep_first",
+		KeepLast:  "keep_last",
+		KeepNone:  "keep_none",
+	}[p]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// DistinctOptions specifies how rows are compared when removing or finding duplicates.
+type DistinctOptions struct {
+	// Keep is the policy applied to groups of duplicates. The zero value means KeepFirst.
+	Keep KeepPolicy
+	// CaseInsensitive indicates whether the comparison should ignore letter case.
+	CaseInsensitive bool
+	// TrimSpace indicates whether leading and trailing whitespace should be ignored.
+	TrimSpace bool
+}
+
+func (t *bDataMatrix) Distinct(keys ...string) (BDataMatrix, error) {
+	return t.DistinctWith(DistinctOptions{}, keys...)
+}
+
+func (t *bDataMatrix) DistinctWith(opts DistinctOptions, keys ...string) (BDataMatrix, error) {
+	groups, order, err := t.groupDuplicates(opts, keys)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []int
+	for _, k := range order {
+		group := groups[k]
+		switch opts.Keep {
+		case 0, KeepFirst:
+			indexes = append(indexes, group[0])
+		case KeepLast:
+			indexes = append(indexes, group[len(group)-1])
+		case KeepNone:
+			if len(group) == 1 {
+				indexes = append(indexes, group[0])
+			}
+		default:
+			return nil, fmt.Errorf("unknown keep policy: %v", opts.Keep)
+		}
+	}
+	sort.Ints(indexes)
+	return t.GetRows(indexes...)
+}
+
+func (t *bDataMatrix) Duplicates(keys ...string) (BDataMatrix, []int, error) {
+	return t.DuplicatesWith(DistinctOptions{}, keys...)
+}
+
+func (t *bDataMatrix) DuplicatesWith(opts DistinctOptions, keys ...string) (BDataMatrix, []int, error) {
+	groups, order, err := t.groupDuplicates(opts, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	var indexes []int
+	for _, k := range order {
+		if group := groups[k]; len(group) > 1 {
+			indexes = append(indexes, group...)
+		}
+	}
+	sort.Ints(indexes)
+	nm, err := t.GetRows(indexes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nm, indexes, nil
+}
+
+// groupDuplicates groups row indexes by their comparison key. The keys are returned in order of first appearance.
+func (t *bDataMatrix) groupDuplicates(opts DistinctOptions, keys []string) (map[string][]int, []string, error) {
+	if len(keys) == 0 {
+		keys = t.header
+	}
+	idxs := make([]int, len(keys))
+	for i, key := range keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+		idxs[i] = idx
+	}
+	groups := make(map[string][]int)
+	var order []string
+	values := make([]string, len(idxs))
+	for i, row := range t.rows {
+		for j, idx := range idxs {
+			v := row[idx]
+			if opts.TrimSpace {
+				v = strings.TrimSpace(v)
+			}
+			if opts.CaseInsensitive {
+				v = strings.ToLower(v)
+			}
+			values[j] = v
+		}
+		k := encodeKey(values)
+		if _, exists := groups[k]; !exists {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], i)
+	}
+	return groups, order, nil
+}
@@ -0,0 +1,99 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDistinct tests Distinct and DistinctWith.
+func TestDistinct(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "25"},
+		{"3", "alice ", "31"},
+		{"4", "Bob", "26"},
+		{"5", "Carol", "40"},
+	}, "ID", "Name", "Age")
+	result, err := matrix.Distinct()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.LenRows() != 5 {
+		t.Fatalf("expected 5 rows, got %d", result.LenRows())
+	}
+
+	result, _ = matrix.Distinct("Name")
+	if result.LenRows() != 4 {
+		t.Fatalf("expected 4 rows, got %d", result.LenRows())
+	}
+	if id, _ := result.GetRowData(1, "ID"); id != "2" {
+		t.Fatalf("expected first Bob to be kept, got ID %s", id)
+	}
+
+	tests := []struct {
+		keep     KeepPolicy
+		expected []string
+	}{
+		{KeepFirst, []string{"1", "2", "5"}},
+		{KeepLast, []string{"3", "4", "5"}},
+		{KeepNone, []string{"5"}},
+	}
+	for _, tt := range tests {
+		opts := DistinctOptions{Keep: tt.keep, CaseInsensitive: true, TrimSpace: true}
+		result, err = matrix.DistinctWith(opts, "Name")
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.keep, err)
+		}
+		ids, _ := result.GetColumn("ID")
+		if len(ids) != len(tt.expected) {
+			t.Fatalf("%s: expected IDs %v, got %v", tt.keep, tt.expected, ids)
+		}
+		for i := range ids {
+			if ids[i] != tt.expected[i] {
+				t.Fatalf("%s: expected IDs %v, got %v", tt.keep, tt.expected, ids)
+			}
+		}
+	}
+
+	if _, err = matrix.DistinctWith(DistinctOptions{Keep: KeepPolicy(9)}); err == nil {
+		t.Fatal("expected error for unknown keep policy, got nil")
+	}
+	if _, err = matrix.Distinct("Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if KeepPolicy(0).String() != "unknown" {
+		t.Fatal("expected unknown keep policy name")
+	}
+}
+
+// TestDuplicates tests Duplicates and DuplicatesWith.
+func TestDuplicates(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "25"},
+		{"3", "alice ", "31"},
+		{"4", "Bob", "26"},
+		{"5", "Carol", "40"},
+	}, "ID", "Name", "Age")
+	result, indexes, err := matrix.Duplicates("Name")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.LenRows() != 2 || len(indexes) != 2 || indexes[0] != 1 || indexes[1] != 3 {
+		t.Fatalf("expected rows 1 and 3, got %v", indexes)
+	}
+
+	_, indexes, _ = matrix.DuplicatesWith(DistinctOptions{CaseInsensitive: true, TrimSpace: true}, "Name")
+	if len(indexes) != 4 || indexes[0] != 0 || indexes[1] != 1 || indexes[2] != 2 || indexes[3] != 3 {
+		t.Fatalf("expected rows 0 to 3, got %v", indexes)
+	}
+
+	result, indexes, _ = matrix.Duplicates()
+	if result.LenRows() != 0 || len(indexes) != 0 {
+		t.Fatalf("expected no duplicates, got %v", indexes)
+	}
+
+	if _, _, err = matrix.Duplicates("Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
@@ -20,4 +20,43 @@ var (
 
 	// ErrDeleteLastColumn is returned when try to delete the last column.
 	ErrDeleteLastColumn = errors.New("unable to delete last column")
+
+	// ErrSchemaMismatch is returned when a value does not match the kind of its column.
+	ErrSchemaMismatch = errors.New("schema mismatch")
+
+	// ErrValidationFailed is returned when a validation report holds violations.
+	ErrValidationFailed = errors.New("validation failed")
+
+	// ErrNoPrimaryKey is returned when a key operation is used without a declared primary key.
+	ErrNoPrimaryKey = errors.New("no primary key declared")
+
+	// ErrDuplicateKey is returned when a row would duplicate an existing primary key.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrKeyNotFound is returned when no row holds the given primary key.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrPrimaryKeyColumn is returned when trying to delete a column that is part of the primary key.
+	ErrPrimaryKeyColumn = errors.New("column is part of the primary key")
+
+	// ErrChangesetConflict is returned when a changeset does not match the matrix it is applied to.
+	ErrChangesetConflict = errors.New("changeset conflict")
+
+	// ErrInvalidExpression is returned when an expression cannot be parsed.
+	ErrInvalidExpression = errors.New("invalid expression")
+
+	// ErrColumnIndexOutOfRange is returned when the specified column position is invalid.
+	ErrColumnIndexOutOfRange = errors.New("column index out of range")
+
+	// ErrHeaderMismatch is returned when the headers of matrices do not line up.
+	ErrHeaderMismatch = errors.New("header mismatch")
+
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrMalformedInput is returned when imported data cannot be parsed.
+	ErrMalformedInput = errors.New("malformed input")
+
+	// ErrVersionConflict is returned when a matrix is saved over a version that is no longer current.
+	ErrVersionConflict = errors.New("version conflict")
 )
@@ -0,0 +1,318 @@
+package bdatamatrix
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exportBatchSize is the number of rows written by each step of an export. It bounds the memory
+// held by StreamingOutput.Read between two reads.
+const exportBatchSize = 256
+
+func (t *bDataMatrix) WriteCSV(w io.Writer, withHeader bool) error {
+	return t.WriteCSVWith(w, CSVOptions{OmitHeader: !withHeader})
+}
+
+func (t *bDataMatrix) WriteTSV(w io.Writer, withHeader bool) error {
+	return t.WriteCSVWith(w, CSVOptions{Delimiter: '\t', OmitHeader: !withHeader})
+}
+
+func (t *bDataMatrix) WriteJSON(w io.Writer, opts JSONOptions) error {
+	if opts.Columns {
+		return runStream(t.jsonColumnsStream(opts.Compact), w)
+	}
+	return runStream(t.jsonStream(opts.Compact), w)
+}
+
+func (t *bDataMatrix) WriteYAML(w io.Writer) error {
+	return runStream(t.yamlStream(), w)
+}
+
+func (t *bDataMatrix) WriteCustom(w io.Writer, withHeader bool, separator string) error {
+	return runStream(t.customStream(withHeader, separator), w)
+}
+
+// stream starts an export to w and returns the function writing its next step. The function returns
+// io.EOF once every step has been written.
+type stream func(w io.Writer) func() error
+
+// runStream writes every step of s to w.
+func runStream(s stream, w io.Writer) error {
+	next := s(w)
+	for {
+		if err := next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// snapshot returns a copy of t for an Output, so that changes made to t after the Output is created
+// are not part of it. Rows are copied but cell values are shared, and the key index is left out.
+// The copy costs one string header per cell; the Write methods avoid it by reading t directly.
+func (t *bDataMatrix) snapshot() *bDataMatrix {
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = append([]string(nil), row...)
+	}
+	var schema *Schema
+	if t.schema != nil {
+		schema = &Schema{Columns: append([]ColumnSchema(nil), t.schema.Columns...)}
+	}
+	headerIndex := make(map[string]int, len(t.headerIndex))
+	for key, idx := range t.headerIndex {
+		headerIndex[key] = idx
+	}
+	return &bDataMatrix{
+		header:      append([]string(nil), t.header...),
+		rows:        rows,
+		headerIndex: headerIndex,
+		schema:      schema,
+		primaryKey:  append([]string(nil), t.primaryKey...),
+		nulls:       t.selectNulls(t.allIndexes(), nil),
+	}
+}
+
+// exportSteps describes an export as a begin step, one step per batch of items and an end step.
+type exportSteps struct {
+	// count returns the number of items, such as rows. It is called before every batch, so an
+	// export never reads past the end of a matrix that shrank while it was being read.
+	count func() int
+	// begin, when set, writes what precedes the first item.
+	begin func() error
+	// item writes the item at index i.
+	item func(i int) error
+	// flush, when set, is called after the begin step and after every batch of items.
+	flush func() error
+	// end, when set, writes what follows the last item.
+	end func() error
+}
+
+// next returns the function writing the next step of the export.
+func (s exportSteps) next() func() error {
+	call := func(fn func() error) error {
+		if fn == nil {
+			return nil
+		}
+		return fn()
+	}
+	i, started, done := 0, false, false
+	return func() error {
+		switch {
+		case done:
+			return io.EOF
+		case !started:
+			started = true
+			if err := call(s.begin); err != nil {
+				return err
+			}
+			return call(s.flush)
+		case i < s.count():
+			for stop := min(i+exportBatchSize, s.count()); i < stop; i++ {
+				if err := s.item(i); err != nil {
+					return err
+				}
+			}
+			return call(s.flush)
+		default:
+			done = true
+			if err := call(s.end); err != nil {
+				return err
+			}
+			return call(s.flush)
+		}
+	}
+}
+
+// jsonStream exports the matrix as an array of row objects, identical to marshaling DataMap with
+// null cells as nil.
+func (t *bDataMatrix) jsonStream(compact bool) stream {
+	return func(w io.Writer) func() error {
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				_, err := io.WriteString(w, "[")
+				return err
+			},
+			item: func(i int) error {
+				var data []byte
+				var err error
+				prefix := ","
+				if compact {
+					data, err = json.Marshal(t.rowMapWithNulls(i))
+				} else {
+					data, err = json.MarshalIndent(t.rowMapWithNulls(i), "  ", "  ")
+					prefix += "\n  "
+				}
+				if err != nil {
+					return err
+				}
+				if i == 0 {
+					prefix = prefix[1:]
+				}
+				if _, err = io.WriteString(w, prefix); err != nil {
+					return err
+				}
+				_, err = w.Write(data)
+				return err
+			},
+			end: func() error {
+				closing := "]"
+				if !compact && t.LenRows() > 0 {
+					closing = "\n]"
+				}
+				_, err := io.WriteString(w, closing)
+				return err
+			},
+		}.next()
+	}
+}
+
+// jsonColumnsStream exports the matrix as a single object mapping every column to the array of its
+// values, in header order.
+func (t *bDataMatrix) jsonColumnsStream(compact bool) stream {
+	return func(w io.Writer) func() error {
+		return exportSteps{
+			count: t.LenColumns,
+			begin: func() error {
+				_, err := io.WriteString(w, "{")
+				return err
+			},
+			item: func(j int) error {
+				values := make([]any, t.LenRows())
+				for i, row := range t.rows {
+					if !t.isNullAt(i, j) {
+						values[i] = row[j]
+					}
+				}
+				key, err := json.Marshal(t.header[j])
+				if err != nil {
+					return err
+				}
+				var data []byte
+				prefix, colon := ",", ":"
+				if compact {
+					data, err = json.Marshal(values)
+				} else {
+					data, err = json.MarshalIndent(values, "  ", "  ")
+					prefix, colon = ",\n  ", ": "
+				}
+				if err != nil {
+					return err
+				}
+				if j == 0 {
+					prefix = prefix[1:]
+				}
+				_, err = io.WriteString(w, prefix+string(key)+colon+string(data))
+				return err
+			},
+			end: func() error {
+				closing := "}"
+				if !compact {
+					closing = "\n}"
+				}
+				_, err := io.WriteString(w, closing)
+				return err
+			},
+		}.next()
+	}
+}
+
+// yamlStream exports the matrix as a sequence of row mappings, identical to marshaling DataMap with
+// null cells as nil.
+func (t *bDataMatrix) yamlStream() stream {
+	return func(w io.Writer) func() error {
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				if t.LenRows() > 0 {
+					return nil
+				}
+				_, err := io.WriteString(w, "[]\n")
+				return err
+			},
+			item: func(i int) error {
+				data, err := yaml.Marshal([]map[string]any{t.rowMapWithNulls(i)})
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(data)
+				return err
+			},
+		}.next()
+	}
+}
+
+// customStream exports the matrix with values joined by separator and rows joined by new lines.
+func (t *bDataMatrix) customStream(withHeader bool, separator string) stream {
+	return func(w io.Writer) func() error {
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				if !withHeader {
+					return nil
+				}
+				_, err := io.WriteString(w, joinCustom(t.header, separator))
+				return err
+			},
+			item: func(i int) error {
+				line := joinCustom(t.rows[i], separator)
+				if withHeader || i > 0 {
+					line = "\n" + line
+				}
+				_, err := io.WriteString(w, line)
+				return err
+			},
+		}.next()
+	}
+}
+
+// joinCustom joins the values of a row of a custom export, quoting the values that need it.
+func joinCustom(values []string, separator string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteCustom(v, separator)
+	}
+	return strings.Join(quoted, separator)
+}
+
+// bytesStream exports data that has already been generated.
+func bytesStream(data []byte) stream {
+	return func(w io.Writer) func() error {
+		done := false
+		return func() error {
+			if done {
+				return io.EOF
+			}
+			done = true
+			_, err := w.Write(data)
+			return err
+		}
+	}
+}
+
+// errorStream exports nothing and fails with err.
+func errorStream(err error) stream {
+	return func(io.Writer) func() error {
+		return func() error {
+			return err
+		}
+	}
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
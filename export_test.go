@@ -0,0 +1,227 @@
+package bdatamatrix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/iotest"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestExportMatchesMarshal tests that streamed exports are identical to marshaling the whole matrix.
+func TestExportMatchesMarshal(t *testing.T) {
+	for _, rows := range []int{0, exportBatchSize*2 + 10} {
+		matrix, _ := New("ID", "Name", "Note")
+		for i := 0; i < exportBatchSize*2+10; i++ {
+			matrix.AddRow(strconv.Itoa(i), "name, \"quoted\"", "<b>")
+		}
+		matrix.SetNull(3, "Note")
+		if rows == 0 {
+			matrix.Clear()
+		}
+		data := matrix.(*bDataMatrix).dataMapWithNulls()
+
+		expected, _ := json.MarshalIndent(data, "", "  ")
+		if got := matrix.ToJSON(false).String(); got != string(expected) {
+			t.Fatalf("rows %d: pretty JSON differs from json.MarshalIndent:\n%s", rows, got)
+		}
+		expected, _ = json.Marshal(data)
+		if got := matrix.ToJSON(true).String(); got != string(expected) {
+			t.Fatalf("rows %d: compact JSON differs from json.Marshal:\n%s", rows, got)
+		}
+		expected, _ = yaml.Marshal(data)
+		if got := matrix.ToYAML().String(); got != string(expected) {
+			t.Fatalf("rows %d: YAML differs from yaml.Marshal:\n%s", rows, got)
+		}
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.WriteAll(matrix.Data(true))
+		if got := matrix.ToCSV(true).String(); got != buf.String() {
+			t.Fatalf("rows %d: CSV differs from csv.Writer:\n%s", rows, got)
+		}
+
+		compact := matrix.ToJSONWith(JSONOptions{Compact: true, Columns: true}).Bytes()
+		var indented bytes.Buffer
+		json.Indent(&indented, compact, "", "  ")
+		if got := matrix.ToJSONWith(JSONOptions{Columns: true}).String(); got != indented.String() {
+			t.Fatalf("rows %d: pretty column JSON differs from json.Indent:\n%s", rows, got)
+		}
+	}
+}
+
+// TestOutputRead tests reading an Output as an io.Reader.
+func TestOutputRead(t *testing.T) {
+	matrix, _ := New("ID", "Name", "Note")
+	for i := 0; i < exportBatchSize*2+10; i++ {
+		matrix.AddRow(strconv.Itoa(i), "name, \"quoted\"", "<b>")
+	}
+	matrix.SetNull(3, "Note")
+	expected := matrix.ToCSV(true).String()
+
+	got, err := io.ReadAll(iotest.OneByteReader(matrix.ToCSV(true)))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(got) != expected {
+		t.Fatalf("expected read data to match String")
+	}
+	if err = iotest.TestReader(matrix.ToCustom(true, ";"), []byte(matrix.ToCustom(true, ";").String())); err != nil {
+		t.Fatalf("expected a well-behaved reader, got %v", err)
+	}
+}
+
+// TestOutputSnapshot tests that an Output holds the matrix as it was when the Output was created,
+// and that its data is generated once.
+func TestOutputSnapshot(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+	out := matrix.ToCSV(true)
+	matrix.UpdateRowColumn(0, "Name", "Bob")
+	matrix.AddRow("2", "Carol")
+	matrix.SetNull(0, "ID")
+	if got := out.String(); got != "ID,Name\n1,Alice\n" {
+		t.Fatalf("expected the matrix before the changes, got %q", got)
+	}
+
+	generated := 0
+	counted := &outputData{stream: func(w io.Writer) func() error {
+		generated++
+		return bytesStream([]byte("data"))(w)
+	}}
+	if counted.String() != string(counted.Bytes()) || counted.Err() != nil {
+		t.Fatalf("expected String and Bytes to match without error")
+	}
+	var buf bytes.Buffer
+	if _, err := counted.WriteTo(&buf); err != nil || buf.String() != "data" {
+		t.Fatalf("unexpected WriteTo result: %v, %q", err, buf.String())
+	}
+	if generated != 1 {
+		t.Fatalf("expected the data to be generated once, got %d", generated)
+	}
+}
+
+// plainOutput implements only the methods of Output, as implementations outside the package may.
+type plainOutput []byte
+
+func (o plainOutput) Write(name string, mode os.FileMode) error { return os.WriteFile(name, o, mode) }
+func (o plainOutput) Bytes() []byte                             { return o }
+func (o plainOutput) String() string                            { return string(o) }
+
+var _ Output = plainOutput(nil)
+
+// TestWriteCSV tests WriteCSV, WriteTo and writing to a file.
+func TestWriteCSV(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+
+	var buf bytes.Buffer
+	if err := matrix.WriteCSV(&buf, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if buf.String() != "ID,Name\n1,Alice\n" {
+		t.Fatalf("unexpected CSV output: %q", buf.String())
+	}
+
+	buf.Reset()
+	n, err := matrix.ToTSV(false).WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != "1\tAlice\n" {
+		t.Fatalf("unexpected WriteTo result: %d, %v, %q", n, err, buf.String())
+	}
+
+	name := filepath.Join(t.TempDir(), "out.json")
+	if err = matrix.ToJSON(true).Write(name, 0644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(name)
+	if string(data) != `[{"ID":"1","Name":"Alice"}]` {
+		t.Fatalf("unexpected file content: %s", data)
+	}
+}
+
+var errWrite = errors.New("write failed")
+
+// failingWriter fails once more than n bytes have been written.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		written := w.n
+		w.n = 0
+		return written, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+// TestExportWriterFailure tests that every exporter reports a failing writer.
+func TestExportWriterFailure(t *testing.T) {
+	matrix, _ := New("ID", "Name", "Note")
+	for i := 0; i < exportBatchSize*2+10; i++ {
+		matrix.AddRow(strconv.Itoa(i), "name, \"quoted\"", "<b>")
+	}
+	matrix.SetNull(3, "Note")
+	exports := map[string]func(w io.Writer) error{
+		"csv":          func(w io.Writer) error { return matrix.WriteCSV(w, true) },
+		"tsv":          func(w io.Writer) error { return matrix.WriteTSV(w, true) },
+		"json":         func(w io.Writer) error { return matrix.WriteJSON(w, JSONOptions{}) },
+		"json columns": func(w io.Writer) error { return matrix.WriteJSON(w, JSONOptions{Columns: true}) },
+		"yaml":         func(w io.Writer) error { return matrix.WriteYAML(w) },
+		"custom":       func(w io.Writer) error { return matrix.WriteCustom(w, true, ";") },
+	}
+	for name, export := range exports {
+		for _, n := range []int{0, 100, 10000} {
+			if err := export(&failingWriter{n: n}); !errors.Is(err, errWrite) {
+				t.Fatalf("%s after %d bytes: expected write error, got %v", name, n, err)
+			}
+		}
+	}
+	out := matrix.ToCSV(true)
+	if _, err := out.WriteTo(&failingWriter{n: 10}); !errors.Is(err, errWrite) {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if !errors.Is(out.Err(), errWrite) {
+		t.Fatalf("expected Err to report the write error, got %v", out.Err())
+	}
+	if out.String() == "" || out.Err() != nil {
+		t.Fatalf("expected a later generation to succeed, got %v", out.Err())
+	}
+}
+
+// TestExportGenerationFailure tests that a failed export cannot be mistaken for data.
+func TestExportGenerationFailure(t *testing.T) {
+	matrix, _ := New("ID", "Name", "Note")
+	for i := 0; i < exportBatchSize*2+10; i++ {
+		matrix.AddRow(strconv.Itoa(i), "name, \"quoted\"", "<b>")
+	}
+	matrix.SetNull(3, "Note")
+	// A quote is not a valid CSV delimiter, so the export fails when it is generated.
+	out := matrix.ToCSVWith(CSVOptions{Delimiter: '"'})
+	if out.Err() == nil {
+		t.Fatalf("expected an error")
+	}
+	if out.Bytes() != nil || out.String() != "" {
+		t.Fatalf("expected no data from a failed export, got %q", out.String())
+	}
+	if _, err := io.ReadAll(out); err == nil || err != out.Err() {
+		t.Fatalf("expected Read to report the error, got %v", err)
+	}
+
+	name := filepath.Join(t.TempDir(), "out.csv")
+	if err := out.Write(name, 0644); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be left behind, got %v", err)
+	}
+
+	if err := matrix.ToJSON(true).Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,654 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// expr is a compiled expression evaluated against a single row.
+type expr func(row []string) (string, error)
+
+// compileExpr compiles an expression, resolving column references through the header of t.
+//
+// The expression language supports:
+//   - Literals: numbers, strings in double or single quotes, true and false.
+//   - Column references: bare names such as Price, or bracketed names such as [Unit Price].
+//   - Arithmetic: + - * / %, on int64 values when both operands are integers. The + operator adds
+//     when both values are numbers and concatenates them otherwise; use concat to join numbers
+//     such as phone numbers or zero-padded codes.
+//   - Comparisons: == != < <= > >=, numeric when both operands are numbers.
+//   - Logic: && || !.
+//   - Functions: if, coalesce, concat, upper, lower, trim, len, substr, parse_date and format_date.
+func compileExpr(s string, t *bDataMatrix) (expr, error) {
+	tokens, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens, t: t}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected '%s' at position %d", ErrInvalidExpression, tok.text, tok.pos)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenColumn
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexExpr(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			start := i
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: s[start:i], pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidExpression, start)
+				}
+				if s[i] == '\\' && i+1 < len(s) {
+					sb.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				if rune(s[i]) == r {
+					i++
+					break
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		case r == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated column reference at position %d", ErrInvalidExpression, i)
+			}
+			tokens = append(tokens, token{kind: tokenColumn, text: s[i+1 : i+end], pos: i})
+			i += end + 1
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(s) {
+				r, size = utf8.DecodeRuneInString(s[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: s[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected '%c' at position %d", ErrInvalidExpression, r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(s)}), nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+	t      *bDataMatrix
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators.
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("%w: expected '%s' at position %d, got '%s'", ErrInvalidExpression, op, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) (string, error) {
+			a, err := l(row)
+			if err != nil {
+				return "", err
+			}
+			if truthy(a) {
+				return formatBool(true), nil
+			}
+			b, err := right(row)
+			return formatBool(truthy(b)), err
+		}
+	}
+}
+
+func (p *exprParser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) (string, error) {
+			a, err := l(row)
+			if err != nil {
+				return "", err
+			}
+			if !truthy(a) {
+				return formatBool(false), nil
+			}
+			b, err := right(row)
+			return formatBool(truthy(b)), err
+		}
+	}
+}
+
+func (p *exprParser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return func(row []string) (string, error) {
+		a, b, err := evalPair(left, right, row)
+		if err != nil {
+			return "", err
+		}
+		c := compareOperands(a, b)
+		switch op {
+		case "==":
+			return formatBool(c == 0), nil
+		case "!=":
+			return formatBool(c != 0), nil
+		case "<":
+			return formatBool(c < 0), nil
+		case "<=":
+			return formatBool(c <= 0), nil
+		case ">":
+			return formatBool(c > 0), nil
+		default:
+			return formatBool(c >= 0), nil
+		}
+	}, nil
+}
+
+func (p *exprParser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			left = addition(left, right)
+			continue
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(op, left, right)
+	}
+}
+
+func (p *exprParser) parseUnary() (expr, error) {
+	op, ok := p.accept("-", "!")
+	if !ok {
+		return p.parsePrimary()
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if op == "!" {
+		return func(row []string) (string, error) {
+			v, err := operand(row)
+			return formatBool(!truthy(v)), err
+		}, nil
+	}
+	return func(row []string) (string, error) {
+		v, err := operand(row)
+		if err != nil {
+			return "", err
+		}
+		if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil && i != math.MinInt64 {
+			return strconv.FormatInt(-i, 10), nil
+		}
+		f, ok := parseNumber(v)
+		if !ok {
+			return "", fmt.Errorf("operator - expects a number, got '%s'", v)
+		}
+		return formatFloat(-f), nil
+	}, nil
+}
+
+func (p *exprParser) parsePrimary() (expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid number '%s' at position %d", ErrInvalidExpression, tok.text, tok.pos)
+		}
+		return constant(tok.text), nil
+	case tokenString:
+		return constant(tok.text), nil
+	case tokenColumn:
+		return p.column(tok.text)
+	case tokenIdent:
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "true", "false":
+			return constant(tok.text), nil
+		}
+		return p.column(tok.text)
+	case tokenOperator:
+		if tok.text == "(" {
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unexpected '%s' at position %d", ErrInvalidExpression, tok.text, tok.pos)
+}
+
+func (p *exprParser) column(key string) (expr, error) {
+	idx, exists := p.t.headerIndex[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	return func(row []string) (string, error) {
+		return row[idx], nil
+	}, nil
+}
+
+func (p *exprParser) parseCall(name token) (expr, error) {
+	var args []expr
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok = p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	f, exists := exprFunctions[strings.ToLower(name.text)]
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown function '%s' at position %d", ErrInvalidExpression, name.text, name.pos)
+	}
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		return nil, fmt.Errorf("%w: wrong number of arguments for %s at position %d", ErrInvalidExpression, name.text, name.pos)
+	}
+	return func(row []string) (string, error) {
+		return f.call(row, args)
+	}, nil
+}
+
+type exprFunction struct {
+	minArgs int
+	maxArgs int
+	call    func(row []string, args []expr) (string, error)
+}
+
+var exprFunctions = map[string]exprFunction{
+	"if": {3, 3, func(row []string, args []expr) (string, error) {
+		cond, err := args[0](row)
+		if err != nil {
+			return "", err
+		}
+		if truthy(cond) {
+			return args[1](row)
+		}
+		return args[2](row)
+	}},
+	"coalesce": {1, -1, func(row []string, args []expr) (string, error) {
+		for _, arg := range args {
+			v, err := arg(row)
+			if err != nil || v != "" {
+				return v, err
+			}
+		}
+		return "", nil
+	}},
+	"concat": {1, -1, func(row []string, args []expr) (string, error) {
+		values, err := evalAll(args, row)
+		return strings.Join(values, ""), err
+	}},
+	"upper":  stringFunction(strings.ToUpper),
+	"lower":  stringFunction(strings.ToLower),
+	"trim":   stringFunction(strings.TrimSpace),
+	"len":    stringFunction(func(s string) string { return strconv.Itoa(utf8.RuneCountInString(s)) }),
+	"substr": {2, 3, callSubstr},
+	"parse_date": {2, 2, func(row []string, args []expr) (string, error) {
+		values, err := evalAll(args, row)
+		if err != nil || values[0] == "" {
+			return "", err
+		}
+		tm, err := time.Parse(values[1], values[0])
+		if err != nil {
+			return "", err
+		}
+		return tm.Format(time.RFC3339), nil
+	}},
+	"format_date": {2, 2, func(row []string, args []expr) (string, error) {
+		values, err := evalAll(args, row)
+		if err != nil || values[0] == "" {
+			return "", err
+		}
+		tm, err := time.Parse(time.RFC3339, values[0])
+		if err != nil {
+			return "", err
+		}
+		return tm.Format(values[1]), nil
+	}},
+}
+
+func stringFunction(fn func(string) string) exprFunction {
+	return exprFunction{1, 1, func(row []string, args []expr) (string, error) {
+		v, err := args[0](row)
+		return fn(v), err
+	}}
+}
+
+// callSubstr returns the characters of a string from a zero-based start, optionally limited to a length.
+func callSubstr(row []string, args []expr) (string, error) {
+	values, err := evalAll(args, row)
+	if err != nil {
+		return "", err
+	}
+	runes := []rune(values[0])
+	start, ok := parseNumber(values[1])
+	if !ok {
+		return "", fmt.Errorf("substr expects a numeric start, got '%s'", values[1])
+	}
+	from := min(max(int(start), 0), len(runes))
+	to := len(runes)
+	if len(values) == 3 {
+		length, ok := parseNumber(values[2])
+		if !ok {
+			return "", fmt.Errorf("substr expects a numeric length, got '%s'", values[2])
+		}
+		to = min(from+max(int(length), 0), len(runes))
+	}
+	return string(runes[from:to]), nil
+}
+
+func constant(v string) expr {
+	return func([]string) (string, error) {
+		return v, nil
+	}
+}
+
+func arithmetic(op string, left, right expr) expr {
+	return func(row []string) (string, error) {
+		a, b, err := evalPair(left, right, row)
+		if err != nil {
+			return "", err
+		}
+		return calculate(op, a, b)
+	}
+}
+
+// calculate applies an arithmetic operator to two values.
+func calculate(op, a, b string) (string, error) {
+	fa, okA := parseNumber(a)
+	fb, okB := parseNumber(b)
+	if !okA || !okB {
+		return "", fmt.Errorf("operator %s expects numbers, got '%s' and '%s'", op, a, b)
+	}
+	if (op == "/" || op == "%") && fb == 0 {
+		return "", errors.New("division by zero")
+	}
+	ia, errA := strconv.ParseInt(strings.TrimSpace(a), 10, 64)
+	ib, errB := strconv.ParseInt(strings.TrimSpace(b), 10, 64)
+	if errA == nil && errB == nil {
+		if v, ok := intArithmetic(op, ia, ib); ok {
+			return strconv.FormatInt(v, 10), nil
+		}
+	}
+	switch op {
+	case "+":
+		return formatResult(fa + fb), nil
+	case "-":
+		return formatResult(fa - fb), nil
+	case "*":
+		return formatResult(fa * fb), nil
+	case "/":
+		return formatResult(fa / fb), nil
+	default:
+		return formatResult(math.Mod(fa, fb)), nil
+	}
+}
+
+// intArithmetic applies op to two integers. It reports false when the result is not an integer or
+// overflows, so that it is computed on floats instead.
+func intArithmetic(op string, a, b int64) (int64, bool) {
+	switch op {
+	case "+":
+		r := a + b
+		return r, (a^r)&(b^r) >= 0
+	case "-":
+		r := a - b
+		return r, (a^b)&(a^r) >= 0
+	case "*":
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		r := a * b
+		return r, r/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+	case "/":
+		if a%b != 0 || (a == math.MinInt64 && b == -1) {
+			return 0, false
+		}
+		return a / b, true
+	default:
+		if b == -1 {
+			return 0, true
+		}
+		return a % b, true
+	}
+}
+
+// addition adds the values of left and right when both are numbers, and joins them otherwise.
+// Values with surrounding spaces are joined, so that chains such as A + " " + B stay text.
+func addition(left, right expr) expr {
+	return func(row []string) (string, error) {
+		a, b, err := evalPair(left, right, row)
+		if err != nil {
+			return "", err
+		}
+		if _, err = strconv.ParseFloat(a, 64); err != nil {
+			return a + b, nil
+		}
+		if _, err = strconv.ParseFloat(b, 64); err != nil {
+			return a + b, nil
+		}
+		return calculate("+", a, b)
+	}
+}
+
+// formatResult formats the result of float arithmetic rounded to 15 significant digits, the
+// precision of a float64, so that rounding errors such as 0.1 + 0.2 = 0.30000000000000004 do not
+// show.
+func formatResult(f float64) string {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', 15, 64), 64)
+	if err != nil {
+		return formatFloat(f)
+	}
+	return formatFloat(rounded)
+}
+
+// compareOperands compares two operands as numbers when both are numbers, and as strings
+// otherwise.
+func compareOperands(a, b string) int {
+	fa, okA := parseNumber(a)
+	fb, okB := parseNumber(b)
+	if okA && okB {
+		return compareFloats(fa, fb)
+	}
+	return strings.Compare(a, b)
+}
+
+func evalPair(left, right expr, row []string) (string, string, error) {
+	a, err := left(row)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := right(row)
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+func evalAll(args []expr, row []string) ([]string, error) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		v, err := arg(row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// truthy reports whether a value is considered true: empty, "0" and values parsed as false are false.
+func truthy(s string) bool {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s != ""
+}
+
+func formatBool(b bool) string {
+	return strconv.FormatBool(b)
+}
@@ -0,0 +1,229 @@
+package bdatamatrix
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compression defines how output data is compressed when written to a file.
+type Compression int
+
+const (
+	// CompressionNone writes the data uncompressed.
+	CompressionNone Compression = iota + 1
+	// CompressionGzip compresses the data with gzip.
+	CompressionGzip
+	// CompressionZlib compresses the data with zlib.
+	CompressionZlib
+)
+
+func (c Compression) String() string {
+	v, ok := map[Compression]string{
+		CompressionNone: "none",
+		CompressionGzip: "gzip",
+		CompressionZlib: "zlib",
+	}[c]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// WriteOptions specifies how StreamingOutput.WriteWith writes a file.
+type WriteOptions struct {
+	// Compression is the compression of the file. The zero value chooses it from the file extension:
+	// gzip for ".gz", zlib for ".zz" and ".zlib", and none otherwise.
+	Compression Compression
+	// Atomic indicates whether the data is written to a temporary file in the same directory, synced
+	// and then renamed, so that the file never holds a partial export, even after a crash.
+	Atomic bool
+	// CreateDirs indicates whether missing parent directories are created.
+	CreateDirs bool
+	// NoOverwrite indicates whether writing fails when the file already exists. The error satisfies
+	// errors.Is(err, fs.ErrExist).
+	NoOverwrite bool
+}
+
+// compression returns the compression to use for the named file.
+func (opts WriteOptions) compression(name string) Compression {
+	if opts.Compression != 0 {
+		return opts.Compression
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gz":
+		return CompressionGzip
+	case ".zz", ".zlib":
+		return CompressionZlib
+	default:
+		return CompressionNone
+	}
+}
+
+func (o *outputData) WriteWith(name string, mode os.FileMode, opts WriteOptions) error {
+	if opts.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+	}
+	if opts.Atomic {
+		return o.writeAtomic(name, mode, opts)
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if opts.NoOverwrite {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(name, flag, mode)
+	if err != nil {
+		return err
+	}
+	err = o.writeFile(f, opts.compression(name))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// A partial export must not be mistaken for data.
+		_ = os.Remove(name)
+	}
+	return err
+}
+
+// writeAtomic writes the output data to a temporary file and renames it to name once it is synced.
+func (o *outputData) writeAtomic(name string, mode os.FileMode, opts WriteOptions) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	err = o.writeFile(f, opts.compression(name))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		if opts.NoOverwrite {
+			// Unlike a rename, a link fails when the file already exists.
+			if err = os.Link(tmp, name); err == nil {
+				_ = os.Remove(tmp)
+			}
+		} else {
+			err = os.Rename(tmp, name)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(name))
+	return nil
+}
+
+// writeFile writes the output data to f with the given compression.
+func (o *outputData) writeFile(f *os.File, compression Compression) error {
+	buf := bufio.NewWriter(f)
+	var w io.WriteCloser
+	switch compression {
+	case CompressionNone:
+	case CompressionGzip:
+		w = gzip.NewWriter(buf)
+	case CompressionZlib:
+		w = zlib.NewWriter(buf)
+	default:
+		return fmt.Errorf("unknown compression: %v", compression)
+	}
+	if w == nil {
+		if _, err := o.WriteTo(buf); err != nil {
+			return err
+		}
+		return buf.Flush()
+	}
+	if _, err := o.WriteTo(w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// syncDir syncs a directory so that a rename into it survives a crash. Errors are ignored since
+// not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// OpenFile opens a file for reading, transparently decompressing gzip and zlib data. Gzip data is
+// recognized by its content, and zlib data by a ".zz" or ".zlib" extension. The result can be passed
+// to the importers that read from an io.Reader.
+//
+// Example usage:
+//
+//	r, err := OpenFile("export.jsonl.gz")
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer r.Close()
+func OpenFile(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader
+	if (WriteOptions{}).compression(name) == CompressionZlib {
+		r, err = zlib.NewReader(bufio.NewReader(f))
+	} else {
+		r, err = decompress(f)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &fileReader{Reader: r, f: f}, nil
+}
+
+// fileReader reads decompressed data from f.
+type fileReader struct {
+	io.Reader
+	f *os.File
+}
+
+func (r *fileReader) Close() error {
+	var err error
+	if c, ok := r.Reader.(io.Closer); ok {
+		err = c.Close()
+	}
+	return errors.Join(err, r.f.Close())
+}
+
+// gzipMagic holds the first bytes of gzip data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns a reader of the decompressed data of r when r holds gzip data, and of the
+// data of r as is otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == len(gzipMagic) && magic[0] == gzipMagic[0] && magic[1] == gzipMagic[1] {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
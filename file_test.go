@@ -0,0 +1,117 @@
+package bdatamatrix
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	r, err := OpenFile(name)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return string(data)
+}
+
+// TestWriteCompressed tests compression chosen by extension and by option, read back with OpenFile.
+func TestWriteCompressed(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+	expected := "ID,Name\n1,Alice\n"
+	dir := t.TempDir()
+
+	gz := filepath.Join(dir, "out.csv.gz")
+	if err := matrix.ToCSV(true).Write(gz, 0644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	f, _ := os.Open(gz)
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("expected gzip data, got %v", err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != expected {
+		t.Fatalf("expected %q, got %q", expected, data)
+	}
+	f.Close()
+	if got := readFile(t, gz); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	// Gzip data is recognized by its content whatever the extension.
+	renamed := filepath.Join(dir, "out.bin")
+	matrix.ToCSV(true).WriteWith(renamed, 0644, WriteOptions{Compression: CompressionGzip})
+	if got := readFile(t, renamed); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	zz := filepath.Join(dir, "out.csv.zz")
+	if err = matrix.ToCSV(true).Write(zz, 0644); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := readFile(t, zz); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	plain := filepath.Join(dir, "out.gz")
+	matrix.ToCSV(true).WriteWith(plain, 0644, WriteOptions{Compression: CompressionNone})
+	if data, _ := os.ReadFile(plain); string(data) != expected {
+		t.Fatalf("expected uncompressed data, got %q", data)
+	}
+}
+
+// TestWriteAtomic tests atomic writes, parent directory creation and refusing to overwrite.
+func TestWriteAtomic(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a", "b", "out.json")
+
+	if err := matrix.ToJSON(true).WriteWith(name, 0640, WriteOptions{Atomic: true}); err == nil {
+		t.Fatalf("expected an error for a missing directory")
+	}
+	opts := WriteOptions{Atomic: true, CreateDirs: true}
+	if err := matrix.ToJSON(true).WriteWith(name, 0640, opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Fatalf("expected mode 0640, got %v", info.Mode().Perm())
+	}
+
+	opts.NoOverwrite = true
+	if err = matrix.ToCSV(true).WriteWith(name, 0640, opts); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected file exists error, got %v", err)
+	}
+	opts.Atomic = false
+	if err = matrix.ToCSV(true).WriteWith(name, 0640, opts); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected file exists error, got %v", err)
+	}
+	if got := readFile(t, name); got != `[{"ID":"1","Name":"Alice"}]` {
+		t.Fatalf("expected the file to be left unchanged, got %s", got)
+	}
+
+	// A failed atomic write leaves the previous file and no temporary file behind.
+	failing := &outputData{stream: errorStream(errWrite)}
+	if err = failing.WriteWith(name, 0640, WriteOptions{Atomic: true}); !errors.Is(err, errWrite) {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if got := readFile(t, name); got != `[{"ID":"1","Name":"Alice"}]` {
+		t.Fatalf("expected the file to be left unchanged, got %s", got)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(name))
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary file, got %d entries", len(entries))
+	}
+}
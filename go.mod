@@ -2,4 +2,7 @@ module github.com/bearaujus/bdatamatrix
 
 go 1.21
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
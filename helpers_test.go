@@ -0,0 +1,52 @@
+package bdatamatrix
+
+import (
+	"strconv"
+	"testing"
+)
+
+// assertHeader fails the test unless the header of matrix equals expected.
+func assertHeader(t *testing.T, matrix BDataMatrix, expected ...string) {
+	t.Helper()
+	header := matrix.Header()
+	if len(header) != len(expected) {
+		t.Fatalf("expected header %v, got %v", expected, header)
+	}
+	for i := range header {
+		if header[i] != expected[i] {
+			t.Fatalf("expected header %v, got %v", expected, header)
+		}
+	}
+}
+
+// assertColumn fails the test unless the column key of matrix equals expected.
+func assertColumn(t *testing.T, matrix BDataMatrix, key string, expected ...string) {
+	t.Helper()
+	column, err := matrix.GetColumn(key)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(column) != len(expected) {
+		t.Fatalf("expected column %s to be %v, got %v", key, expected, column)
+	}
+	for i := range column {
+		if column[i] != expected[i] {
+			t.Fatalf("expected column %s to be %v, got %v", key, expected, column)
+		}
+	}
+}
+
+// newNumberedMatrix returns a matrix of n rows with an ID counting from zero and a Group
+// alternating between "a" and "b".
+func newNumberedMatrix(n int) BDataMatrix {
+	rows := make([][]string, n)
+	for i := range rows {
+		group := "a"
+		if i%2 == 1 {
+			group = "b"
+		}
+		rows[i] = []string{strconv.Itoa(i), group}
+	}
+	matrix, _ := NewWithData(rows, "ID", "Group")
+	return matrix
+}
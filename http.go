@@ -0,0 +1,494 @@
+package bdatamatrix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HTTPOptions configures the handler returned by NewHTTPHandler.
+type HTTPOptions struct {
+	// DefaultFormat is the format served when neither the format parameter nor the Accept header
+	// selects one: "json", "csv", "tsv", "yaml" or "html". The zero value means "json".
+	DefaultFormat string
+	// MaxLimit is the maximum number of rows of a response, also used when the request has no limit
+	// parameter. The zero value means no maximum.
+	MaxLimit int
+}
+
+// httpFormat describes a format served by the HTTP handler.
+type httpFormat struct {
+	name        string
+	contentType string
+	mediaTypes  []string
+	stream      func(t *bDataMatrix) stream
+}
+
+var httpFormats = []httpFormat{
+	{"json", "application/json", []string{"application/json"}, func(t *bDataMatrix) stream {
+		return t.jsonStream(false)
+	}},
+	{"csv", "text/csv; charset=utf-8", []string{"text/csv"}, func(t *bDataMatrix) stream {
+		return t.csvStream(CSVOptions{})
+	}},
+	{"tsv", "text/tab-separated-values; charset=utf-8", []string{"text/tab-separated-values"}, func(t *bDataMatrix) stream {
+		return t.csvStream(CSVOptions{Delimiter: '\t'})
+	}},
+	{"yaml", "application/yaml", []string{"application/yaml", "application/x-yaml", "text/yaml"}, func(t *bDataMatrix) stream {
+		return t.yamlStream()
+	}},
+	{"html", "text/html; charset=utf-8", []string{"text/html"}, func(t *bDataMatrix) stream {
+		return t.htmlStream()
+	}},
+}
+
+// NewHTTPHandler returns a handler serving the matrix to GET and HEAD requests, whatever the
+// request path. The matrix must not be modified while the handler is serving it. A matrix that was
+// not created by this package is copied through its Header, Rows and IsNull methods when the
+// handler is created, and a nil matrix is answered with 500 Internal Server Error.
+//
+// The format is chosen by the format query parameter, or negotiated from the Accept header. The
+// rows can be shaped with query parameters, applied in this order:
+//   - filter: An expression in the language of AddComputedColumn. Rows for which it is false or
+//     empty are left out, such as filter=Age >= 30 && Country == "ID".
+//   - sort: Comma-separated columns to order rows by, descending when prefixed with "-". Numbers
+//     are compared as numbers, such as sort=-Age,Name.
+//   - offset and limit: The number of rows to skip, and the maximum number of rows to serve.
+//   - columns: Comma-separated columns to serve, in order, such as columns=ID,Name.
+//
+// Responses are streamed as they are generated. They carry an ETag and are answered with 304 Not
+// Modified when it matches If-None-Match. They are compressed with gzip when the client accepts it, and the X-Total-Count header holds the
+// number of rows matching the filter before the offset and limit apply.
+//
+// Example usage:
+//
+//	http.Handle("/users", NewHTTPHandler(matrix, HTTPOptions{MaxLimit: 1000}))
+//	// GET /users?filter=Age>30&sort=-Age&columns=ID,Name&format=csv
+func NewHTTPHandler(matrix BDataMatrix, opts HTTPOptions) http.Handler {
+	if opts.DefaultFormat == "" {
+		opts.DefaultFormat = "json"
+	}
+	t, err := httpMatrix(matrix)
+	return &httpHandler{matrix: t, err: err, opts: opts}
+}
+
+type httpHandler struct {
+	matrix *bDataMatrix
+	// err is the error answering every request when the handler has no matrix to serve.
+	err  error
+	opts HTTPOptions
+}
+
+// httpMatrix returns matrix as a *bDataMatrix, copying other implementations of BDataMatrix
+// together with their null cells, primary key and schema.
+func httpMatrix(matrix BDataMatrix) (*bDataMatrix, error) {
+	if t, ok := matrix.(*bDataMatrix); ok && t != nil {
+		return t, nil
+	} else if ok || matrix == nil {
+		return nil, errors.New("nil matrix")
+	}
+	header, rows := matrix.Header(), matrix.Rows()
+	nm, err := NewWithData(rows, header...)
+	if err != nil {
+		return nil, err
+	}
+	t := nm.(*bDataMatrix)
+	for i := range rows {
+		for j, key := range header {
+			if null, _ := matrix.IsNull(i, key); null {
+				t.markNull(i, j, true)
+			}
+		}
+	}
+	if err = t.SetPrimaryKey(matrix.PrimaryKey()...); err != nil {
+		return nil, err
+	}
+	if schema, ok := matrix.Schema(); ok {
+		if err = t.ApplySchema(schema); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.err != nil {
+		http.Error(w, h.err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serveMatrix(w, r, h.matrix, h.opts, true)
+}
+
+// serveMatrix serves t in the negotiated format, shaped by the query parameters of r. etags
+// indicates whether the response carries an ETag computed from its content.
+func serveMatrix(w http.ResponseWriter, r *http.Request, t *bDataMatrix, opts HTTPOptions, etags bool) {
+	query := r.URL.Query()
+	format, status, err := negotiateFormat(query.Get("format"), r.Header.Get("Accept"), opts.DefaultFormat)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	result, total, err := t.httpQuery(query, opts.MaxLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	serveOutput(w, r, format, result, strconv.Itoa(total), etags)
+}
+
+// serveOutput streams result in format to w, handling gzip and, when etags is true, ETags. The ETag
+// is computed from the cells of result rather than from the data, so the data is generated once,
+// as it is sent, and the response has no Content-Length.
+func serveOutput(w http.ResponseWriter, r *http.Request, format httpFormat, result *bDataMatrix, total string, etags bool) {
+	gzipped := acceptsGzip(r.Header.Get("Accept-Encoding"))
+	header := w.Header()
+	header.Set("Vary", "Accept, Accept-Encoding")
+	header.Set("X-Total-Count", total)
+	if etags {
+		etag := contentETag(format, result)
+		if gzipped {
+			// A compressed representation needs its own entity tag.
+			header.Set("ETag", strings.TrimSuffix(etag, `"`)+`-gzip"`)
+		} else {
+			header.Set("ETag", etag)
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	header.Set("Content-Type", format.contentType)
+	if gzipped {
+		header.Set("Content-Encoding", "gzip")
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body := &responseWriter{w: w}
+	var out io.Writer = body
+	var gz *gzip.Writer
+	if gzipped {
+		gz = gzip.NewWriter(body)
+		out = gz
+	}
+	_, err := (&outputData{stream: format.stream(result)}).WriteTo(out)
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		if !body.started {
+			header.Del("Content-Encoding")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The status is already sent, so the connection is aborted to keep the client from taking
+		// the truncated body for a complete one.
+		panic(http.ErrAbortHandler)
+	}
+	if !body.started {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// responseWriter writes to an http.ResponseWriter, sending the status with the first byte so that
+// an error found before then can still be answered with an error status.
+type responseWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if !rw.started {
+		rw.started = true
+		rw.w.WriteHeader(http.StatusOK)
+	}
+	return rw.w.Write(p)
+}
+
+// contentETag returns the entity tag of result served in format, a hash of the format and of the
+// header, cells and null cells of result.
+func contentETag(format httpFormat, result *bDataMatrix) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, encodeKey(append([]string{format.name}, result.header...))+"\n")
+	nulls := make([]string, result.LenColumns())
+	for i, row := range result.rows {
+		for j := range nulls {
+			nulls[j] = strconv.FormatBool(result.isNullAt(i, j))
+		}
+		_, _ = io.WriteString(h, encodeKey(row)+"\n"+encodeKey(nulls)+"\n")
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// httpQuery applies the filter, sort, offset, limit and columns parameters of query. It returns the
+// resulting matrix and the number of rows matching the filter.
+func (t *bDataMatrix) httpQuery(query url.Values, maxLimit int) (*bDataMatrix, int, error) {
+	indexes := t.allIndexes()
+	if filter := query.Get("filter"); filter != "" {
+		e, err := compileExpr(filter, t)
+		if err != nil {
+			return nil, 0, fmt.Errorf("filter: %w", err)
+		}
+		kept := indexes[:0]
+		for _, i := range indexes {
+			v, err := e(t.rows[i])
+			if err != nil {
+				return nil, 0, fmt.Errorf("filter: row %d: %w", i, err)
+			}
+			if truthy(v) {
+				kept = append(kept, i)
+			}
+		}
+		indexes = kept
+	}
+	total := len(indexes)
+
+	if s := query.Get("sort"); s != "" {
+		var keys []SortKey
+		var idxs []int
+		for _, name := range splitList(s) {
+			key := SortKey{Column: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-"), Numeric: true}
+			idx, exists := t.headerIndex[key.Column]
+			if !exists {
+				return nil, 0, fmt.Errorf("sort: %w: %s", ErrColumnNotFound, key.Column)
+			}
+			keys = append(keys, key)
+			idxs = append(idxs, idx)
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			for k, key := range keys {
+				if c := key.compare(t.rows[indexes[a]][idxs[k]], t.rows[indexes[b]][idxs[k]]); c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	offset, err := queryInt(query, "offset", 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	limit, err := queryInt(query, "limit", maxLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	indexes = indexes[min(offset, len(indexes)):]
+	if query.Has("limit") || maxLimit > 0 {
+		indexes = indexes[:min(limit, len(indexes))]
+	}
+
+	cols := make([]int, t.LenColumns())
+	for j := range cols {
+		cols[j] = j
+	}
+	if s := query.Get("columns"); s != "" {
+		cols = cols[:0]
+		for _, name := range splitList(s) {
+			idx, exists := t.headerIndex[name]
+			if !exists {
+				return nil, 0, fmt.Errorf("columns: %w: %s", ErrColumnNotFound, name)
+			}
+			cols = append(cols, idx)
+		}
+		if len(cols) == 0 {
+			return nil, 0, fmt.Errorf("columns: %w", ErrEmptyHeader)
+		}
+	}
+	result := &bDataMatrix{header: make([]string, len(cols)), rows: make([][]string, len(indexes))}
+	for j, idx := range cols {
+		result.header[j] = t.header[idx]
+	}
+	for i, index := range indexes {
+		row := make([]string, len(cols))
+		for j, idx := range cols {
+			row[j] = t.rows[index][idx]
+		}
+		result.rows[i] = row
+	}
+	result.nulls = t.selectNulls(indexes, cols)
+	if err = result.calculateHeaderIndex(); err != nil {
+		return nil, 0, fmt.Errorf("columns: %w", err)
+	}
+	return result, total, nil
+}
+
+// queryInt returns the non-negative integer parameter key of query, or def when it is absent.
+func queryInt(query url.Values, key string, def int) (int, error) {
+	if !query.Has(key) {
+		return def, nil
+	}
+	n, err := strconv.Atoi(query.Get(key))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s (%s) must be a non-negative integer", key, query.Get(key))
+	}
+	return n, nil
+}
+
+// splitList splits a comma-separated parameter, trimming spaces and dropping empty entries.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// negotiateFormat chooses the format named by the format parameter, or else the format most
+// preferred by the Accept header, preferring def on ties. It returns the HTTP status to answer
+// with when no format can be served.
+func negotiateFormat(param, accept, def string) (httpFormat, int, error) {
+	if param != "" {
+		for _, f := range httpFormats {
+			if strings.EqualFold(f.name, param) {
+				return f, 0, nil
+			}
+		}
+		return httpFormat{}, http.StatusBadRequest, fmt.Errorf("unknown format: %s", param)
+	}
+	var best httpFormat
+	bestQ, bestSpecificity := 0.0, -1
+	for _, f := range httpFormats {
+		q, specificity := 1.0, 0
+		if strings.TrimSpace(accept) != "" {
+			q, specificity = acceptQuality(accept, f.mediaTypes)
+		}
+		if q == 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && (specificity > bestSpecificity || specificity == bestSpecificity && f.name == def)) {
+			best, bestQ, bestSpecificity = f, q, specificity
+		}
+	}
+	if bestQ == 0 {
+		return httpFormat{}, http.StatusNotAcceptable, fmt.Errorf("no acceptable format for: %s", accept)
+	}
+	return best, 0, nil
+}
+
+// acceptQuality returns the quality the Accept header gives to the media types, from 0 to 1, and the
+// specificity of the range it comes from: 2 for an exact match, 1 for a type wildcard such as
+// text/* and 0 for */*. More specific ranges take precedence.
+func acceptQuality(accept string, mediaTypes []string) (float64, int) {
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		for _, t := range mediaTypes {
+			s := -1
+			switch {
+			case mediaType == t:
+				s = 2
+			case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(t, strings.TrimSuffix(mediaType, "*")):
+				s = 1
+			case mediaType == "*/*":
+				s = 0
+			}
+			if s > specificity {
+				quality, specificity = q, s
+			}
+		}
+	}
+	return quality, specificity
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		params = strings.ReplaceAll(params, " ", "")
+		if q, ok := strings.CutPrefix(params, "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err != nil || v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// etagMatches reports whether the If-None-Match header matches etag, in its plain or gzip form.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag || tag == strings.TrimSuffix(etag, `"`)+`-gzip"` {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlStream exports the matrix as an HTML document holding a table, with null cells left empty.
+func (t *bDataMatrix) htmlStream() stream {
+	return func(w io.Writer) func() error {
+		var buf bytes.Buffer
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				buf.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"></head>\n<body>\n<table>\n<thead>\n<tr>")
+				for _, key := range t.header {
+					buf.WriteString("<th>" + html.EscapeString(key) + "</th>")
+				}
+				buf.WriteString("</tr>\n</thead>\n<tbody>\n")
+				return nil
+			},
+			item: func(i int) error {
+				buf.WriteString("<tr>")
+				for j, value := range t.rows[i] {
+					if t.isNullAt(i, j) {
+						value = ""
+					}
+					buf.WriteString("<td>" + html.EscapeString(value) + "</td>")
+				}
+				buf.WriteString("</tr>\n")
+				return nil
+			},
+			end: func() error {
+				buf.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+				return nil
+			},
+			flush: func() error {
+				_, err := buf.WriteTo(w)
+				return err
+			},
+		}.next()
+	}
+}
@@ -0,0 +1,241 @@
+package bdatamatrix
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// serve sends a request to handler and returns the response.
+func serve(handler http.Handler, method, target string, header map[string]string) *http.Response {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Result()
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestHTTPHandlerQuery tests the filter, sort, columns, limit and offset parameters.
+func TestHTTPHandlerQuery(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "9"},
+		{"3", "<Carol>", "41"},
+		{"4", "Dan", "25"},
+	}, "ID", "Name", "Age")
+	matrix.SetNull(3, "Age")
+	handler := NewHTTPHandler(matrix, HTTPOptions{})
+
+	resp := serve(handler, http.MethodGet, "/people?filter="+url.QueryEscape(`Age >= 10 || Name == "Bob"`)+"&sort=-Age&columns=Name,Age&format=csv", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got, expected := readBody(t, resp), "Name,Age\n<Carol>,41\nAlice,30\nBob,9\n"; got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if got := resp.Header.Get("X-Total-Count"); got != "3" {
+		t.Fatalf("expected total count 3, got %s", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %s", got)
+	}
+
+	resp = serve(handler, http.MethodGet, "/?sort=ID&offset=1&limit=2&columns=ID,Age", nil)
+	if got, expected := readBody(t, resp), `[
+  {
+    "Age": "9",
+    "ID": "2"
+  },
+  {
+    "Age": "41",
+    "ID": "3"
+  }
+]`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	limited := NewHTTPHandler(matrix, HTTPOptions{MaxLimit: 1, DefaultFormat: "tsv"})
+	resp = serve(limited, http.MethodGet, "/?limit=5&offset=3", nil)
+	if got, expected := readBody(t, resp), "ID\tName\tAge\n4\tDan\t\n"; got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	for _, target := range []string{
+		"/?filter=" + url.QueryEscape("Age >"),
+		"/?sort=Missing",
+		"/?columns=ID,Missing",
+		"/?columns=ID,ID",
+		"/?limit=-1",
+		"/?offset=x",
+		"/?format=xls",
+	} {
+		if resp = serve(handler, http.MethodGet, target, nil); resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", target, resp.StatusCode)
+		}
+	}
+	if resp = serve(handler, http.MethodPost, "/", nil); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", resp.StatusCode)
+	}
+
+	// Other implementations of BDataMatrix are copied, keeping their null cells.
+	wrapped := NewHTTPHandler(struct{ BDataMatrix }{matrix}, HTTPOptions{})
+	resp = serve(wrapped, http.MethodGet, "/?format=json&filter=ID==4", nil)
+	if got, expected := readBody(t, resp), "[\n  {\n    \"Age\": null,\n    \"ID\": \"4\",\n    \"Name\": \"Dan\"\n  }\n]"; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if resp = serve(NewHTTPHandler(nil, HTTPOptions{}), http.MethodGet, "/", nil); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500 for a nil matrix, got %d", resp.StatusCode)
+	}
+}
+
+// TestHTTPHandlerFormats tests format negotiation from the Accept header.
+func TestHTTPHandlerFormats(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "9"},
+		{"3", "<Carol>", "41"},
+		{"4", "Dan", "25"},
+	}, "ID", "Name", "Age")
+	matrix.SetNull(3, "Age")
+	handler := NewHTTPHandler(matrix, HTTPOptions{})
+	for accept, expected := range map[string]string{
+		"":              "application/json",
+		"*/*":           "application/json",
+		"text/csv, */*": "text/csv; charset=utf-8",
+		"text/*":        "text/csv; charset=utf-8",
+		"application/x-yaml;q=0.9, text/tab-separated-values;q=0.5":       "application/yaml",
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": "text/html; charset=utf-8",
+	} {
+		resp := serve(handler, http.MethodGet, "/", map[string]string{"Accept": accept})
+		if got := resp.Header.Get("Content-Type"); got != expected {
+			t.Fatalf("Accept %q: expected %s, got %s", accept, expected, got)
+		}
+	}
+	resp := serve(handler, http.MethodGet, "/", map[string]string{"Accept": "image/png, */*;q=0"})
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", resp.StatusCode)
+	}
+
+	resp = serve(handler, http.MethodGet, "/?format=html&limit=1", nil)
+	body := readBody(t, resp)
+	if !strings.Contains(body, "<tr><th>ID</th><th>Name</th><th>Age</th></tr>") ||
+		!strings.Contains(body, "<tr><td>1</td><td>Alice</td><td>30</td></tr>") {
+		t.Fatalf("unexpected HTML %s", body)
+	}
+	resp = serve(handler, http.MethodGet, "/?format=html&filter=ID==3", nil)
+	if body = readBody(t, resp); !strings.Contains(body, "<td>&lt;Carol&gt;</td>") {
+		t.Fatalf("expected escaped HTML, got %s", body)
+	}
+}
+
+// TestHTTPHandlerCaching tests ETags, HEAD requests and gzip.
+func TestHTTPHandlerCaching(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30"},
+		{"2", "Bob", "9"},
+		{"3", "<Carol>", "41"},
+		{"4", "Dan", "25"},
+	}, "ID", "Name", "Age")
+	matrix.SetNull(3, "Age")
+	handler := NewHTTPHandler(matrix, HTTPOptions{})
+
+	resp := serve(handler, http.MethodGet, "/?format=csv", nil)
+	plain := readBody(t, resp)
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+	resp = serve(handler, http.MethodGet, "/?format=csv", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusNotModified || readBody(t, resp) != "" {
+		t.Fatalf("expected status 304 without body, got %d", resp.StatusCode)
+	}
+	resp = serve(handler, http.MethodGet, "/?format=csv&limit=1", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 for other data, got %d", resp.StatusCode)
+	}
+
+	resp = serve(handler, http.MethodHead, "/?format=csv", nil)
+	if readBody(t, resp) != "" || resp.Header.Get("ETag") != etag {
+		t.Fatalf("expected HEAD without body and with the ETag of GET, got %s", resp.Header.Get("ETag"))
+	}
+
+	resp = serve(handler, http.MethodGet, "/?format=csv", map[string]string{"Accept-Encoding": "br, gzip"})
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatal("expected gzip encoding")
+	}
+	gzipETag := resp.Header.Get("ETag")
+	if gzipETag == etag {
+		t.Fatal("expected the gzip ETag to differ")
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(gz)
+	if string(data) != plain {
+		t.Fatalf("expected %q, got %q", plain, data)
+	}
+	resp = serve(handler, http.MethodGet, "/?format=csv", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": gzipETag})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected status 304 for gzip ETag, got %d", resp.StatusCode)
+	}
+	resp = serve(handler, http.MethodGet, "/", map[string]string{"Accept-Encoding": "gzip;q=0"})
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Fatal("expected no encoding when gzip is refused")
+	}
+
+	// The body is generated once, as it is sent.
+	generated := 0
+	counted := httpFormat{name: "csv", contentType: "text/csv", stream: func(t *bDataMatrix) stream {
+		generated++
+		return t.csvStream(CSVOptions{})
+	}}
+	w := httptest.NewRecorder()
+	serveOutput(w, httptest.NewRequest(http.MethodGet, "/", nil), counted, matrix.(*bDataMatrix), "4", true)
+	if generated != 1 || w.Body.String() != plain {
+		t.Fatalf("expected a single generation, got %d: %q", generated, w.Body.String())
+	}
+
+	// An error before the first byte is answered with 500, and an error after it aborts the response.
+	failing := func(data string) httpFormat {
+		return httpFormat{name: "csv", contentType: "text/csv", stream: func(*bDataMatrix) stream {
+			return func(w io.Writer) func() error {
+				return func() error {
+					if _, err := io.WriteString(w, data); err != nil {
+						return err
+					}
+					return errors.New("export failed")
+				}
+			}
+		}}
+	}
+	w = httptest.NewRecorder()
+	serveOutput(w, httptest.NewRequest(http.MethodGet, "/", nil), failing(""), matrix.(*bDataMatrix), "4", false)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "export failed") {
+		t.Fatalf("expected status 500, got %d: %q", w.Code, w.Body.String())
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Fatalf("expected the response to be aborted, got %v", r)
+			}
+		}()
+		serveOutput(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), failing("ID\n"), matrix.(*bDataMatrix), "4", false)
+	}()
+}
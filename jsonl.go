@@ -0,0 +1,319 @@
+package bdatamatrix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultHeaderSampleLines is the number of lines FromJSONL reads to infer the header when
+// JSONLOptions.HeaderSampleLines is zero.
+const DefaultHeaderSampleLines = 100
+
+// MalformedPolicy defines what an importer does with input it cannot parse.
+type MalformedPolicy int
+
+const (
+	// MalformedFail stops the import at the first malformed line.
+	MalformedFail MalformedPolicy = iota + 1
+	// MalformedSkip skips malformed lines and reports them.
+	MalformedSkip
+)
+
+func (p MalformedPolicy) String() string {
+	v, ok := map[MalformedPolicy]string{
+		MalformedFail: "fail",
+		MalformedSkip: "skip",
+	}[p]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// LineError describes a line of imported data that cannot be parsed.
+type LineError struct {
+	// Line is the one-based number of the line.
+	Line int
+	// Err is the parse error.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// JSONLOptions specifies how FromJSONLWith imports JSON Lines.
+type JSONLOptions struct {
+	// HeaderSampleLines is the number of lines whose keys make up the header, in order of first
+	// appearance. Keys first seen after these lines are ignored. The zero value means
+	// DefaultHeaderSampleLines.
+	HeaderSampleLines int
+	// Malformed is the policy applied to lines that are not JSON objects. The zero value means
+	// MalformedFail.
+	Malformed MalformedPolicy
+}
+
+func (t *bDataMatrix) ToJSONL() StreamingOutput {
+	return &outputData{stream: t.snapshot().jsonlStream()}
+}
+
+func (t *bDataMatrix) WriteJSONL(w io.Writer) error {
+	return runStream(t.jsonlStream(), w)
+}
+
+// jsonlStream exports the matrix as one JSON object per line, with keys in header order.
+func (t *bDataMatrix) jsonlStream() stream {
+	return func(w io.Writer) func() error {
+		var buf bytes.Buffer
+		keys := make([][]byte, t.LenColumns())
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				for j, key := range t.header {
+					data, err := json.Marshal(key)
+					if err != nil {
+						return err
+					}
+					keys[j] = data
+				}
+				return nil
+			},
+			item: func(i int) error {
+				buf.WriteByte('{')
+				for j, value := range t.rows[i] {
+					if j > 0 {
+						buf.WriteByte(',')
+					}
+					buf.Write(keys[j])
+					buf.WriteByte(':')
+					if t.isNullAt(i, j) {
+						buf.WriteString("null")
+						continue
+					}
+					data, err := json.Marshal(value)
+					if err != nil {
+						return err
+					}
+					buf.Write(data)
+				}
+				buf.WriteString("}\n")
+				return nil
+			},
+			flush: func() error {
+				_, err := buf.WriteTo(w)
+				return err
+			},
+		}.next()
+	}
+}
+
+// FromJSONL imports JSON Lines, one JSON object per line, stopping at the first malformed line.
+// See FromJSONLWith for the details.
+//
+// Example usage:
+//
+//	f, err := OpenFile("events.jsonl.gz")
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer f.Close()
+//	matrix, err := FromJSONL(f)
+func FromJSONL(r io.Reader) (BDataMatrix, error) {
+	m, _, err := FromJSONLWith(r, JSONLOptions{})
+	return m, err
+}
+
+// FromJSONLWith imports JSON Lines, one JSON object per line, reading r as a stream. Gzip input is
+// decompressed transparently.
+//
+// The header is the union of the keys of the first opts.HeaderSampleLines lines. Keys missing from a
+// line and null values become null cells, numbers and booleans keep their JSON text, and nested
+// objects and arrays are stored as compact JSON. Blank lines are ignored.
+//
+// Returns the matrix and, when opts.Malformed is MalformedSkip, the skipped lines. With
+// MalformedFail, the error of the first malformed line satisfies errors.Is(err, ErrMalformedInput)
+// and errors.As with a *LineError.
+func FromJSONLWith(r io.Reader, opts JSONLOptions) (BDataMatrix, []LineError, error) {
+	if opts.HeaderSampleLines <= 0 {
+		opts.HeaderSampleLines = DefaultHeaderSampleLines
+	}
+	if opts.Malformed == 0 {
+		opts.Malformed = MalformedFail
+	}
+	if opts.Malformed != MalformedFail && opts.Malformed != MalformedSkip {
+		return nil, nil, fmt.Errorf("unknown malformed policy: %v", opts.Malformed)
+	}
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var skipped []LineError
+	// next returns the next object of the input, or io.EOF.
+	br := bufio.NewReader(dr)
+	line := 0
+	next := func() (jsonObject, error) {
+		for {
+			data, err := br.ReadBytes('\n')
+			if len(data) == 0 && err != nil {
+				return jsonObject{}, err
+			}
+			if err != nil && err != io.EOF {
+				return jsonObject{}, err
+			}
+			line++
+			if len(bytes.TrimSpace(data)) == 0 {
+				continue
+			}
+			obj, parseErr := parseJSONObject(data)
+			if parseErr == nil {
+				return obj, nil
+			}
+			lineErr := LineError{Line: line, Err: parseErr}
+			if opts.Malformed == MalformedFail {
+				return jsonObject{}, fmt.Errorf("%w: %w", ErrMalformedInput, &lineErr)
+			}
+			skipped = append(skipped, lineErr)
+		}
+	}
+
+	// The first lines are held until the header is known.
+	var sample []jsonObject
+	var header []string
+	seen := make(map[string]struct{})
+	for len(sample) < opts.HeaderSampleLines {
+		obj, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range obj.keys {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				header = append(header, key)
+			}
+		}
+		sample = append(sample, obj)
+	}
+	if len(header) == 0 {
+		return nil, nil, ErrEmptyHeader
+	}
+	nm, err := New(header...)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := nm.(*bDataMatrix)
+	for _, obj := range sample {
+		m.addValues(obj.values)
+	}
+	for {
+		obj, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		m.addValues(obj.values)
+	}
+	return m, skipped, nil
+}
+
+// jsonObject holds the values of a JSON object, with keys in their order of appearance. Null
+// values are absent from values.
+type jsonObject struct {
+	keys   []string
+	values map[string]string
+}
+
+// parseJSONObject parses a single JSON object, keeping the order of its keys.
+func parseJSONObject(data []byte) (jsonObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonObject{}, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return jsonObject{}, errors.New("expected a JSON object")
+	}
+	obj := jsonObject{values: make(map[string]string)}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return jsonObject{}, err
+		}
+		key := tok.(string)
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return jsonObject{}, err
+		}
+		if _, exists := obj.values[key]; !exists {
+			obj.keys = append(obj.keys, key)
+		}
+		value, null, err := jsonValueString(raw)
+		if err != nil {
+			return jsonObject{}, err
+		}
+		if null {
+			delete(obj.values, key)
+			continue
+		}
+		obj.values[key] = value
+	}
+	if _, err = dec.Token(); err != nil {
+		return jsonObject{}, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return jsonObject{}, errors.New("unexpected data after the JSON object")
+	}
+	return obj, nil
+}
+
+// jsonValueString converts a JSON value to the string stored in a cell.
+func jsonValueString(raw json.RawMessage) (string, bool, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		return "", true, nil
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		var s string
+		err := json.Unmarshal(trimmed, &s)
+		return s, false, err
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		var buf bytes.Buffer
+		err := json.Compact(&buf, trimmed)
+		return buf.String(), false, err
+	default:
+		return string(trimmed), false, nil
+	}
+}
+
+// addValues appends a row holding the values of the columns of the header, with null cells for
+// the columns missing from values.
+func (t *bDataMatrix) addValues(values map[string]string) {
+	row := make([]string, t.LenColumns())
+	var nulls []int
+	for j, key := range t.header {
+		value, exists := values[key]
+		if !exists {
+			nulls = append(nulls, j)
+		}
+		row[j] = value
+	}
+	// The matrix has no schema or primary key yet, so adding the row cannot fail.
+	_ = t.AddRow(row...)
+	for _, j := range nulls {
+		t.markNull(t.LenRows()-1, j, true)
+	}
+}
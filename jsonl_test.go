@@ -0,0 +1,87 @@
+package bdatamatrix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestToJSONL tests ToJSONL.
+func TestToJSONL(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}, {"2", "Bob"}}, "ID", "Name")
+	matrix.SetNull(1, "Name")
+	expected := "{\"ID\":\"1\",\"Name\":\"Alice\"}\n{\"ID\":\"2\",\"Name\":null}\n"
+	if got := matrix.ToJSONL().String(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	imported, err := FromJSONL(matrix.ToJSONL())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, imported, "ID", "Name")
+	if null, _ := imported.IsNull(1, "Name"); !null {
+		t.Fatalf("expected null cell to survive a round trip")
+	}
+}
+
+// TestFromJSONL tests header inference and value conversion of FromJSONL.
+func TestFromJSONL(t *testing.T) {
+	input := `{"id": 1, "name": "Alice", "tags": ["a", "b"]}
+
+{"id": 2.5, "active": true, "name": null}
+{"id": 3, "late": "ignored"}
+`
+	matrix, _, err := FromJSONLWith(strings.NewReader(input), JSONLOptions{HeaderSampleLines: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "id", "name", "tags", "active")
+	assertColumn(t, matrix, "id", "1", "2.5", "3")
+	assertColumn(t, matrix, "tags", `["a","b"]`, "", "")
+	assertColumn(t, matrix, "active", "", "true", "")
+	if null, _ := matrix.IsNull(1, "name"); !null {
+		t.Fatalf("expected JSON null to be a null cell")
+	}
+	if null, _ := matrix.IsNull(0, "active"); !null {
+		t.Fatalf("expected a missing key to be a null cell")
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(input))
+	zw.Close()
+	compressed, err := FromJSONL(&buf)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if compressed.LenRows() != 3 {
+		t.Fatalf("expected gzip input to be decompressed, got %d rows", compressed.LenRows())
+	}
+}
+
+// TestFromJSONLMalformed tests the malformed line policies of FromJSONLWith.
+func TestFromJSONLMalformed(t *testing.T) {
+	input := "{\"id\": 1}\nnot json\n[1, 2]\n{\"id\": 2}\n"
+
+	_, err := FromJSONL(strings.NewReader(input))
+	var lineErr *LineError
+	if !errors.Is(err, ErrMalformedInput) || !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("expected malformed input error on line 2, got %v", err)
+	}
+
+	matrix, skipped, err := FromJSONLWith(strings.NewReader(input), JSONLOptions{Malformed: MalformedSkip})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "id", "1", "2")
+	if len(skipped) != 2 || skipped[0].Line != 2 || skipped[1].Line != 3 {
+		t.Fatalf("expected lines 2 and 3 to be skipped, got %v", skipped)
+	}
+
+	if _, err = FromJSONL(strings.NewReader("")); !errors.Is(err, ErrEmptyHeader) {
+		t.Fatalf("expected empty header error, got %v", err)
+	}
+}
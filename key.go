@@ -0,0 +1,151 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (t *bDataMatrix) SetPrimaryKey(keys ...string) error {
+	if len(keys) == 0 {
+		t.primaryKey = nil
+		t.keyIndex = nil
+		return nil
+	}
+	// Aliases are resolved so that the key always names header columns.
+	names := make([]string, len(keys))
+	seen := make(map[string]struct{}, len(keys))
+	for i, key := range keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+		names[i] = t.header[idx]
+		if _, ok := seen[names[i]]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateHeader, key)
+		}
+		seen[names[i]] = struct{}{}
+	}
+	old := t.primaryKey
+	t.primaryKey = names
+	if err := t.reindexKeys(); err != nil {
+		t.primaryKey = old
+		_ = t.reindexKeys()
+		return err
+	}
+	return nil
+}
+
+func (t *bDataMatrix) PrimaryKey() []string {
+	return append([]string(nil), t.primaryKey...)
+}
+
+func (t *bDataMatrix) GetByKey(key ...string) ([]string, error) {
+	index, err := t.indexByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), t.rows[index]...), nil
+}
+
+func (t *bDataMatrix) UpdateByKey(key []string, values ...string) error {
+	index, err := t.indexByKey(key)
+	if err != nil {
+		return err
+	}
+	return t.UpdateRow(index, values...)
+}
+
+func (t *bDataMatrix) DeleteByKey(key ...string) error {
+	index, err := t.indexByKey(key)
+	if err != nil {
+		return err
+	}
+	return t.DeleteRow(index)
+}
+
+func (t *bDataMatrix) Upsert(values ...string) error {
+	if t.keyIndex == nil {
+		return ErrNoPrimaryKey
+	}
+	if len(values) != t.LenColumns() {
+		return fmt.Errorf("row length (%d) does not match header length (%d)", len(values), t.LenColumns())
+	}
+	if index, exists := t.keyIndex[t.rowKey(values)]; exists {
+		return t.UpdateRow(index, values...)
+	}
+	return t.AddRow(values...)
+}
+
+// indexByKey returns the index of the row holding the given primary key values.
+func (t *bDataMatrix) indexByKey(key []string) (int, error) {
+	if t.keyIndex == nil {
+		return 0, ErrNoPrimaryKey
+	}
+	if len(key) != len(t.primaryKey) {
+		return 0, fmt.Errorf("key length (%d) does not match primary key length (%d)", len(key), len(t.primaryKey))
+	}
+	index, exists := t.keyIndex[encodeKey(key)]
+	if !exists {
+		return 0, fmt.Errorf("%w: %s", ErrKeyNotFound, strings.Join(key, ", "))
+	}
+	return index, nil
+}
+
+// rowKey returns the encoded primary key of a full row.
+func (t *bDataMatrix) rowKey(values []string) string {
+	key := make([]string, len(t.primaryKey))
+	for i, k := range t.primaryKey {
+		key[i] = values[t.headerIndex[k]]
+	}
+	return encodeKey(key)
+}
+
+// checkKey returns an error if values would duplicate the primary key of a row other than index.
+// Use -1 as index for rows that are not yet part of the matrix.
+func (t *bDataMatrix) checkKey(index int, values []string) error {
+	if t.keyIndex == nil {
+		return nil
+	}
+	if existing, exists := t.keyIndex[t.rowKey(values)]; exists && existing != index {
+		return fmt.Errorf("%w: row %d already holds this key", ErrDuplicateKey, existing)
+	}
+	return nil
+}
+
+// isKeyColumn reports whether the column is part of the primary key.
+func (t *bDataMatrix) isKeyColumn(key string) bool {
+	for _, k := range t.primaryKey {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// reindexKeys rebuilds the primary key index from the rows. It must be called after any
+// operation that moves or removes rows.
+func (t *bDataMatrix) reindexKeys() error {
+	if len(t.primaryKey) == 0 {
+		t.keyIndex = nil
+		return nil
+	}
+	t.keyIndex = make(map[string]int, t.LenRows())
+	for i, row := range t.rows {
+		k := t.rowKey(row)
+		if existing, exists := t.keyIndex[k]; exists {
+			return fmt.Errorf("%w: rows %d and %d hold the same key", ErrDuplicateKey, existing, i)
+		}
+		t.keyIndex[k] = i
+	}
+	return nil
+}
+
+// encodeKey joins key values into a single unambiguous map key.
+func encodeKey(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ",")
+}
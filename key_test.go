@@ -0,0 +1,161 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSetPrimaryKey tests SetPrimaryKey.
+func TestSetPrimaryKey(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "EU", "Alice"},
+		{"2", "EU", "Bob"},
+		{"1", "US", "Carol"},
+	}, "ID", "Region", "Name")
+	if err := matrix.SetPrimaryKey("ID", "Region"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pk := matrix.PrimaryKey(); len(pk) != 2 || pk[0] != "ID" {
+		t.Fatalf("unexpected primary key %v", pk)
+	}
+	if err := matrix.SetPrimaryKey("ID"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if pk := matrix.PrimaryKey(); len(pk) != 2 {
+		t.Fatalf("expected previous primary key to be kept, got %v", pk)
+	}
+	if err := matrix.SetPrimaryKey("Age"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.SetPrimaryKey(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := matrix.GetByKey("1"); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expected no primary key error, got %v", err)
+	}
+}
+
+// TestKeyedMutations tests that mutations respect and maintain the primary key.
+func TestKeyedMutations(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "EU", "Alice"},
+		{"2", "EU", "Bob"},
+		{"1", "US", "Carol"},
+	}, "ID", "Region", "Name")
+	if err := matrix.SetPrimaryKey("ID", "Region"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := matrix.AddRow("2", "EU", "Dave"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if err := matrix.UpdateRow(0, "2", "EU", "Alice"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if err := matrix.UpdateRowColumn(2, "Region", "EU"); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if err := matrix.DeleteColumn("Region"); !errors.Is(err, ErrPrimaryKeyColumn) {
+		t.Fatalf("expected primary key column error, got %v", err)
+	}
+
+	// Updating the key of a row moves it in the index.
+	if err := matrix.UpdateRowColumn(2, "Region", "APAC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := matrix.GetByKey("1", "US"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+	row, err := matrix.GetByKey("1", "APAC")
+	if err != nil || row[2] != "Carol" {
+		t.Fatalf("expected Carol, got %v, %v", row, err)
+	}
+
+	// Row positions shift after sorting and deleting.
+	if err = matrix.SortByDesc("Name"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = matrix.DeleteRow(0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	row, err = matrix.GetByKey("2", "EU")
+	if err != nil || row[2] != "Bob" {
+		t.Fatalf("expected Bob, got %v, %v", row, err)
+	}
+	row, err = matrix.GetByKey("1", "EU")
+	if err != nil || row[2] != "Alice" {
+		t.Fatalf("expected Alice, got %v, %v", row, err)
+	}
+
+	// Copies keep their own index.
+	copied := matrix.Copy()
+	matrix.Clear()
+	if _, err = matrix.GetByKey("1", "EU"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error after clear, got %v", err)
+	}
+	if err = matrix.AddRow("1", "EU", "Alice"); err != nil {
+		t.Fatalf("expected no error after clear, got %v", err)
+	}
+	if _, err = copied.GetByKey("2", "EU"); err != nil {
+		t.Fatalf("expected copy to keep its rows, got %v", err)
+	}
+}
+
+// TestByKey tests GetByKey, UpdateByKey, DeleteByKey and Upsert.
+func TestByKey(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "EU", "Alice"},
+		{"2", "EU", "Bob"},
+		{"1", "US", "Carol"},
+	}, "ID", "Region", "Name")
+	if err := matrix.SetPrimaryKey("ID", "Region"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := matrix.GetByKey("1"); err == nil {
+		t.Fatal("expected error for key length mismatch, got nil")
+	}
+	if err := matrix.UpdateByKey([]string{"2", "EU"}, "2", "EU", "Bobby"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if row, _ := matrix.GetByKey("2", "EU"); row[2] != "Bobby" {
+		t.Fatalf("expected Bobby, got %v", row)
+	}
+	if err := matrix.UpdateByKey([]string{"9", "EU"}, "9", "EU", "Nobody"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+	if err := matrix.DeleteByKey("1", "EU"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if matrix.LenRows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", matrix.LenRows())
+	}
+	if err := matrix.DeleteByKey("1", "EU"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+
+	if err := matrix.Upsert("1", "US", "Caroline"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := matrix.Upsert("3", "US", "Erin"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if matrix.LenRows() != 3 {
+		t.Fatalf("expected 3 rows, got %d", matrix.LenRows())
+	}
+	if row, _ := matrix.GetByKey("1", "US"); row[2] != "Caroline" {
+		t.Fatalf("expected Caroline, got %v", row)
+	}
+	if err := matrix.Upsert("3"); err == nil {
+		t.Fatal("expected error for row length mismatch, got nil")
+	}
+
+	unkeyed, _ := New("ID")
+	if err := unkeyed.Upsert("1"); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expected no primary key error, got %v", err)
+	}
+	// The returned row is a copy, so changing it leaves the key index intact.
+	row, _ := matrix.GetByKey("1", "US")
+	row[0] = "9"
+	if row, err := matrix.GetByKey("1", "US"); err != nil || row[0] != "1" {
+		t.Fatalf("expected the row to be unchanged, got %v, %v", row, err)
+	}
+}
@@ -0,0 +1,329 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (t *bDataMatrix) SetNull(index int, key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	if index < 0 || index >= t.LenRows() {
+		return fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index)
+	}
+	if key = t.header[idx]; t.isKeyColumn(key) {
+		return fmt.Errorf("%w: %s", ErrPrimaryKeyColumn, key)
+	}
+	t.rows[index][idx] = ""
+	t.markNull(index, idx, true)
+	return nil
+}
+
+func (t *bDataMatrix) IsNull(index int, key string) (bool, error) {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return false, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	if index < 0 || index >= t.LenRows() {
+		return false, fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, index)
+	}
+	return t.isNullAt(index, idx), nil
+}
+
+func (t *bDataMatrix) FillNull(key string, value string) error {
+	return t.fillNull(key, func(int) (string, bool) {
+		return value, true
+	})
+}
+
+func (t *bDataMatrix) FillForward(key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	fills := make([]string, t.LenRows())
+	found := make([]bool, t.LenRows())
+	last, seen := "", false
+	for i, row := range t.rows {
+		if !t.isNullAt(i, idx) {
+			last, seen = row[idx], true
+			continue
+		}
+		fills[i], found[i] = last, seen
+	}
+	return t.fillNull(key, func(i int) (string, bool) {
+		return fills[i], found[i]
+	})
+}
+
+func (t *bDataMatrix) FillBackward(key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	fills := make([]string, t.LenRows())
+	found := make([]bool, t.LenRows())
+	next, seen := "", false
+	for i := t.LenRows() - 1; i >= 0; i-- {
+		if !t.isNullAt(i, idx) {
+			next, seen = t.rows[i][idx], true
+			continue
+		}
+		fills[i], found[i] = next, seen
+	}
+	return t.fillNull(key, func(i int) (string, bool) {
+		return fills[i], found[i]
+	})
+}
+
+func (t *bDataMatrix) FillMode(key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	counts := make(map[string]int)
+	mode, best := "", 0
+	for i, row := range t.rows {
+		if t.isNullAt(i, idx) || row[idx] == "" {
+			continue
+		}
+		counts[row[idx]]++
+		// Ties favor the value that reached the count first.
+		if counts[row[idx]] > best {
+			mode, best = row[idx], counts[row[idx]]
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+	return t.FillNull(key, mode)
+}
+
+func (t *bDataMatrix) FillMean(key string) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	var sum float64
+	count := 0
+	for i, row := range t.rows {
+		if t.isNullAt(i, idx) || row[idx] == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(row[idx]), 64)
+		if err != nil {
+			return fmt.Errorf("row %d: column '%s' value '%s' is not a number", i, key, row[idx])
+		}
+		sum += f
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	return t.FillNull(key, formatFloat(sum/float64(count)))
+}
+
+func (t *bDataMatrix) DropNullRows(keys ...string) error {
+	if len(keys) == 0 {
+		keys = t.header
+	}
+	idxs := make([]int, len(keys))
+	for i, key := range keys {
+		idx, exists := t.headerIndex[key]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+		idxs[i] = idx
+	}
+	t.removeRows(func(i int) bool {
+		for _, idx := range idxs {
+			if t.isNullAt(i, idx) {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func (t *bDataMatrix) DeleteNullColumns() error {
+	if t.LenRows() == 0 {
+		return nil
+	}
+	var nullColumns []string
+	for j, key := range t.header {
+		null := true
+		for i := range t.rows {
+			if !t.isNullAt(i, j) {
+				null = false
+				break
+			}
+		}
+		if null {
+			nullColumns = append(nullColumns, key)
+		}
+	}
+	if len(nullColumns) == t.LenColumns() {
+		return ErrDeleteLastColumn
+	}
+	for _, key := range nullColumns {
+		if err := t.DeleteColumn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillNull replaces the null cells of a column with the value returned by fill, when it returns true.
+func (t *bDataMatrix) fillNull(key string, fill func(index int) (string, bool)) error {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	for i := range t.rows {
+		if !t.isNullAt(i, idx) {
+			continue
+		}
+		value, ok := fill(i)
+		if !ok {
+			continue
+		}
+		if err := t.UpdateRowColumn(i, key, value); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// isNullAt reports whether the cell at row i and column j is null.
+func (t *bDataMatrix) isNullAt(i, j int) bool {
+	return t.nulls != nil && t.nulls[i] != nil && t.nulls[i][j]
+}
+
+// markNull sets the null flag of the cell at row i and column j, allocating the flags when needed.
+func (t *bDataMatrix) markNull(i, j int, null bool) {
+	if !null && !t.isNullAt(i, j) {
+		return
+	}
+	if t.nulls == nil {
+		t.nulls = make([][]bool, t.LenRows())
+	}
+	if t.nulls[i] == nil {
+		t.nulls[i] = make([]bool, t.LenColumns())
+	}
+	t.nulls[i][j] = null
+}
+
+// appendNullColumn extends the null flags after a column has been appended.
+func (t *bDataMatrix) appendNullColumn() {
+	for i, flags := range t.nulls {
+		if flags != nil {
+			t.nulls[i] = append(flags, false)
+		}
+	}
+}
+
+// keepNullColumns drops the null flags of every column whose keep entry is false.
+func (t *bDataMatrix) keepNullColumns(keep []bool) {
+	for i, flags := range t.nulls {
+		if flags == nil {
+			continue
+		}
+		var kept []bool
+		for j, null := range flags {
+			if keep[j] {
+				kept = append(kept, null)
+			}
+		}
+		t.nulls[i] = kept
+	}
+}
+
+// selectNulls returns a copy of the null flags of the given rows, restricted to the given columns when cols is not nil.
+func (t *bDataMatrix) selectNulls(indexes []int, cols []int) [][]bool {
+	if t.nulls == nil {
+		return nil
+	}
+	nulls := make([][]bool, len(indexes))
+	for i, index := range indexes {
+		flags := t.nulls[index]
+		if flags == nil {
+			continue
+		}
+		if cols == nil {
+			nulls[i] = append([]bool(nil), flags...)
+			continue
+		}
+		nulls[i] = make([]bool, len(cols))
+		for j, col := range cols {
+			nulls[i][j] = flags[col]
+		}
+	}
+	return nulls
+}
+
+// reorderRows rearranges the rows so that the row at order[i] moves to position i.
+func (t *bDataMatrix) reorderRows(order []int) {
+	rows := make([][]string, len(order))
+	for i, index := range order {
+		rows[i] = t.rows[index]
+	}
+	if t.nulls != nil {
+		nulls := make([][]bool, len(order))
+		for i, index := range order {
+			nulls[i] = t.nulls[index]
+		}
+		t.nulls = nulls
+	}
+	t.rows = rows
+	_ = t.reindexKeys()
+}
+
+// removeRows removes every row for which drop returns true, in a single pass.
+func (t *bDataMatrix) removeRows(drop func(index int) bool) int {
+	kept := 0
+	for i := range t.rows {
+		if drop(i) {
+			continue
+		}
+		t.rows[kept] = t.rows[i]
+		if t.nulls != nil {
+			t.nulls[kept] = t.nulls[i]
+		}
+		kept++
+	}
+	removed := t.LenRows() - kept
+	t.rows = t.rows[:kept]
+	if t.nulls != nil {
+		t.nulls = t.nulls[:kept]
+	}
+	if removed > 0 {
+		_ = t.reindexKeys()
+	}
+	return removed
+}
+
+// dataMapWithNulls returns the matrix as a slice of maps where null cells are nil.
+func (t *bDataMatrix) dataMapWithNulls() []map[string]any {
+	data := make([]map[string]any, t.LenRows())
+	for i := range t.rows {
+		data[i] = t.rowMapWithNulls(i)
+	}
+	return data
+}
+
+// rowMapWithNulls returns the row at index i as a map where null cells are nil.
+func (t *bDataMatrix) rowMapWithNulls(i int) map[string]any {
+	obj := make(map[string]any, t.LenColumns())
+	for j, key := range t.header {
+		if t.isNullAt(i, j) {
+			obj[key] = nil
+		} else {
+			obj[key] = t.rows[i][j]
+		}
+	}
+	return obj
+}
@@ -0,0 +1,209 @@
+package bdatamatrix
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestSetNull tests SetNull and IsNull.
+func TestSetNull(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30", "EU"},
+		{"2", "Bob", "x", "US"},
+		{"3", "Carol", "40", "EU"},
+		{"4", "Dave", "x", "x"},
+		{"5", "Erin", "", "EU"},
+	}, "ID", "Name", "Age", "Region")
+	for _, cell := range []struct {
+		index int
+		key   string
+	}{{1, "Age"}, {3, "Age"}, {3, "Region"}} {
+		if err := matrix.SetNull(cell.index, cell.key); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	null, err := matrix.IsNull(1, "Age")
+	if err != nil || !null {
+		t.Fatalf("expected null cell, got %v, %v", null, err)
+	}
+	if v, _ := matrix.GetRowData(1, "Age"); v != "" {
+		t.Fatalf("expected null cell to read as empty, got %q", v)
+	}
+	if null, _ = matrix.IsNull(4, "Age"); null {
+		t.Fatal("expected empty cell not to be null")
+	}
+	matrix.UpdateRowColumn(1, "Age", "25")
+	if null, _ = matrix.IsNull(1, "Age"); null {
+		t.Fatal("expected written cell not to be null")
+	}
+
+	// Test nulls follow rows and columns.
+	matrix.SortByDesc("ID")
+	if null, _ = matrix.IsNull(1, "Region"); !null {
+		t.Fatal("expected null to follow its row when sorting")
+	}
+	matrix.AddColumns("Email")
+	matrix.DeleteColumn("Name")
+	if null, _ = matrix.IsNull(1, "Region"); !null {
+		t.Fatal("expected null to follow its column")
+	}
+	sub, _ := matrix.GetRows(1)
+	if null, _ = sub.IsNull(0, "Age"); !null {
+		t.Fatal("expected GetRows to keep nulls")
+	}
+	sub, _ = matrix.GetColumns("Region")
+	if null, _ = sub.IsNull(1, "Region"); !null {
+		t.Fatal("expected GetColumns to keep nulls")
+	}
+	if null, _ = matrix.Copy().IsNull(1, "Region"); !null {
+		t.Fatal("expected Copy to keep nulls")
+	}
+	matrix.DeleteRow(0)
+	if null, _ = matrix.IsNull(0, "Region"); !null {
+		t.Fatal("expected null to follow its row when deleting")
+	}
+
+	// Test errors.
+	if _, err = matrix.IsNull(0, "Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if _, err = matrix.IsNull(9, "Age"); !errors.Is(err, ErrRowIndexOutOfRange) {
+		t.Fatalf("expected row index out of range error, got %v", err)
+	}
+	if err = matrix.SetNull(9, "Age"); !errors.Is(err, ErrRowIndexOutOfRange) {
+		t.Fatalf("expected row index out of range error, got %v", err)
+	}
+	matrix.SetPrimaryKey("ID")
+	if err = matrix.SetNull(0, "ID"); !errors.Is(err, ErrPrimaryKeyColumn) {
+		t.Fatalf("expected primary key column error, got %v", err)
+	}
+}
+
+// TestFillNull tests the fill helpers.
+func TestFillNull(t *testing.T) {
+	base, _ := NewWithData([][]string{
+		{"1", "Alice", "30", "EU"},
+		{"2", "Bob", "x", "US"},
+		{"3", "Carol", "40", "EU"},
+		{"4", "Dave", "x", "x"},
+		{"5", "Erin", "", "EU"},
+	}, "ID", "Name", "Age", "Region")
+	for _, cell := range []struct {
+		index int
+		key   string
+	}{{1, "Age"}, {3, "Age"}, {3, "Region"}} {
+		if err := base.SetNull(cell.index, cell.key); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	matrix := base.Copy()
+	if err := matrix.FillNull("Region", "APAC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Region", "EU", "US", "EU", "APAC", "EU")
+
+	matrix = base.Copy()
+	matrix.FillForward("Age")
+	assertColumn(t, matrix, "Age", "30", "30", "40", "40", "")
+
+	matrix = base.Copy()
+	matrix.FillBackward("Age")
+	assertColumn(t, matrix, "Age", "30", "40", "40", "", "")
+	if null, _ := matrix.IsNull(3, "Age"); null {
+		t.Fatal("expected null to be filled with the next empty value")
+	}
+	matrix.SetNull(4, "Age")
+	matrix.FillBackward("Age")
+	if null, _ := matrix.IsNull(4, "Age"); !null {
+		t.Fatal("expected trailing null to stay null")
+	}
+
+	matrix = base.Copy()
+	matrix.FillMode("Region")
+	assertColumn(t, matrix, "Region", "EU", "US", "EU", "EU", "EU")
+	// Empty values are skipped even when they are the most frequent.
+	graded, _ := NewWithData([][]string{{""}, {""}, {"B"}, {""}}, "Grade")
+	graded.SetNull(3, "Grade")
+	graded.FillMode("Grade")
+	assertColumn(t, graded, "Grade", "", "", "B", "B")
+
+	matrix = base.Copy()
+	if err := matrix.FillMean("Age"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Age", "30", "35", "40", "35", "")
+	if err := matrix.FillMean("Name"); err == nil {
+		t.Fatal("expected error for non-numeric values, got nil")
+	}
+
+	for _, fill := range []func(string) error{matrix.FillForward, matrix.FillBackward, matrix.FillMode, matrix.FillMean} {
+		if err := fill("Gender"); !errors.Is(err, ErrColumnNotFound) {
+			t.Fatalf("expected column not found error, got %v", err)
+		}
+	}
+}
+
+// TestDropNullRows tests DropNullRows and DeleteNullColumns.
+func TestDropNullRows(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "30", "EU"},
+		{"2", "Bob", "x", "US"},
+		{"3", "Carol", "40", "EU"},
+		{"4", "Dave", "x", "x"},
+		{"5", "Erin", "", "EU"},
+	}, "ID", "Name", "Age", "Region")
+	for _, cell := range []struct {
+		index int
+		key   string
+	}{{1, "Age"}, {3, "Age"}, {3, "Region"}} {
+		if err := matrix.SetNull(cell.index, cell.key); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if err := matrix.DropNullRows("Region"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "ID", "1", "2", "3", "5")
+	matrix.DropNullRows()
+	assertColumn(t, matrix, "ID", "1", "3", "5")
+	if err := matrix.DropNullRows("Gender"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+
+	matrix, _ = NewWithData([][]string{{"1", "", ""}, {"2", "", ""}}, "ID", "Blank", "Missing")
+	matrix.SetNull(0, "Missing")
+	matrix.SetNull(1, "Missing")
+	if err := matrix.DeleteNullColumns(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if h := matrix.Header(); len(h) != 2 || h[1] != "Blank" {
+		t.Fatalf("expected only the null column to be deleted, got %v", h)
+	}
+}
+
+// TestNullExport tests that ToJSON and ToYAML emit nulls.
+func TestNullExport(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", ""}, {"2", ""}}, "ID", "Name")
+	matrix.SetNull(1, "Name")
+	var result []map[string]*string
+	if err := json.Unmarshal(matrix.ToJSON(true).Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+	if result[0]["Name"] == nil || result[1]["Name"] != nil {
+		t.Fatalf("expected an empty name and a null name, got %s", matrix.ToJSON(true))
+	}
+	if !strings.Contains(matrix.ToYAML().String(), "Name: null") {
+		t.Fatalf("expected YAML null, got %s", matrix.ToYAML())
+	}
+	result = nil
+	if err := yaml.Unmarshal(matrix.ToYAML().Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal YAML: %v", err)
+	}
+	if result[0]["Name"] == nil || result[1]["Name"] != nil {
+		t.Fatalf("expected an empty name and a null name, got %s", matrix.ToYAML())
+	}
+}
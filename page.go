@@ -0,0 +1,169 @@
+package bdatamatrix
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Page holds one page of rows returned by BDataMatrix.Page, together with pagination metadata.
+type Page struct {
+	// Matrix holds the rows of the page.
+	Matrix BDataMatrix
+	// Number is the one-based number of the page.
+	Number int
+	// Size is the maximum number of rows per page.
+	Size int
+	// TotalRows is the number of rows of the whole matrix.
+	TotalRows int
+	// TotalPages is the number of pages needed to hold every row.
+	TotalPages int
+}
+
+// HasNext reports whether a page follows this one.
+func (p Page) HasNext() bool {
+	return p.Number < p.TotalPages
+}
+
+// CursorPage holds one page of rows returned by BDataMatrix.PageAfter.
+type CursorPage struct {
+	// Matrix holds the rows of the page.
+	Matrix BDataMatrix
+	// NextCursor is the cursor to pass to PageAfter to get the next page. It is empty on the last page.
+	NextCursor string
+}
+
+func (t *bDataMatrix) Slice(start, end int) (BDataMatrix, error) {
+	if start < 0 || start > t.LenRows() {
+		return nil, fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, start)
+	}
+	if end < start || end > t.LenRows() {
+		return nil, fmt.Errorf("%w: %d", ErrRowIndexOutOfRange, end)
+	}
+	return t.view(rowRange(start, end)), nil
+}
+
+func (t *bDataMatrix) Head(n int) BDataMatrix {
+	return t.view(rowRange(0, max(0, min(n, t.LenRows()))))
+}
+
+func (t *bDataMatrix) Tail(n int) BDataMatrix {
+	return t.view(rowRange(t.LenRows()-max(0, min(n, t.LenRows())), t.LenRows()))
+}
+
+func (t *bDataMatrix) Page(pageNum, pageSize int) (Page, error) {
+	if pageNum < 1 {
+		return Page{}, fmt.Errorf("page number (%d) must be at least 1", pageNum)
+	}
+	if pageSize < 1 {
+		return Page{}, fmt.Errorf("page size (%d) must be at least 1", pageSize)
+	}
+	total := t.LenRows()
+	start := min((pageNum-1)*pageSize, total)
+	return Page{
+		Matrix:     t.view(rowRange(start, min(start+pageSize, total))),
+		Number:     pageNum,
+		Size:       pageSize,
+		TotalRows:  total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}, nil
+}
+
+func (t *bDataMatrix) PageAfter(orderBy []SortKey, cursor string, limit int) (CursorPage, error) {
+	if len(orderBy) == 0 {
+		return CursorPage{}, fmt.Errorf("at least one sort key is required")
+	}
+	if limit < 1 {
+		return CursorPage{}, fmt.Errorf("limit (%d) must be at least 1", limit)
+	}
+	// keys holds the sort key values of every row.
+	keys := make([][]string, t.LenRows())
+	for i := range keys {
+		keys[i] = make([]string, len(orderBy))
+	}
+	for k, key := range orderBy {
+		idx, exists := t.headerIndex[key.Column]
+		if !exists {
+			return CursorPage{}, fmt.Errorf("%w: %s", ErrColumnNotFound, key.Column)
+		}
+		for i, row := range t.rows {
+			keys[i][k] = row[idx]
+		}
+	}
+	compare := func(a, b []string) int {
+		for k, key := range orderBy {
+			if c := key.compare(a[k], b[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	order := t.allIndexes()
+	sort.SliceStable(order, func(i, j int) bool {
+		return compare(keys[order[i]], keys[order[j]]) < 0
+	})
+	start := 0
+	if cursor != "" {
+		after, err := decodeCursor(cursor, len(orderBy))
+		if err != nil {
+			return CursorPage{}, err
+		}
+		start = sort.Search(len(order), func(i int) bool {
+			return compare(keys[order[i]], after) > 0
+		})
+	}
+	end := min(start+limit, len(order))
+	result := CursorPage{Matrix: t.view(order[start:end])}
+	if end < len(order) {
+		result.NextCursor = encodeCursor(keys[order[end-1]])
+	}
+	return result, nil
+}
+
+// view returns a matrix holding a copy of the rows at indexes, so that changing it never changes t.
+func (t *bDataMatrix) view(indexes []int) BDataMatrix {
+	rows := make([][]string, len(indexes))
+	for i, index := range indexes {
+		rows[i] = append([]string(nil), t.rows[index]...)
+	}
+	v := &bDataMatrix{
+		header: append([]string(nil), t.header...),
+		rows:   rows,
+		nulls:  t.selectNulls(indexes, nil),
+	}
+	_ = v.calculateHeaderIndex()
+	return v
+}
+
+// rowRange returns the indexes of the rows from start to end.
+func rowRange(start, end int) []int {
+	indexes := make([]int, end-start)
+	for i := range indexes {
+		indexes[i] = start + i
+	}
+	return indexes
+}
+
+// encodeCursor encodes the sort key values of the last row of a page into an opaque cursor.
+func encodeCursor(values []string) string {
+	data, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor returned by encodeCursor, checking it holds n values.
+func decodeCursor(cursor string, n int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var values []string
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(values) != n {
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, n, len(values))
+	}
+	return values, nil
+}
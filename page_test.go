@@ -0,0 +1,113 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSlice tests Slice, Head and Tail.
+func TestSlice(t *testing.T) {
+	matrix := newNumberedMatrix(5)
+
+	slice, err := matrix.Slice(1, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, slice, "ID", "1", "2")
+	// Changing the slice in any way leaves the matrix unchanged.
+	matrix.SetPrimaryKey("ID")
+	if err = slice.UpdateRow(0, "9", "z"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = slice.DeleteRow(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = slice.AddRow("x", "y"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, slice, "ID", "9", "x")
+	assertColumn(t, matrix, "ID", "0", "1", "2", "3", "4")
+	assertColumn(t, matrix, "Group", "a", "b", "a", "b", "a")
+	if row, err := matrix.GetByKey("1"); err != nil || row[0] != "1" {
+		t.Fatalf("expected the key index of the matrix to be unchanged, got %v, %v", row, err)
+	}
+	head := matrix.Head(3)
+	if _, err = head.DeleteWhere(FindRowsQuery{Column: "Group", Operator: OperatorEquals, Value: "a"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, head, "ID", "1")
+	assertColumn(t, matrix, "ID", "0", "1", "2", "3", "4")
+	if _, err = matrix.Slice(3, 2); !errors.Is(err, ErrRowIndexOutOfRange) {
+		t.Fatalf("expected row index out of range error, got %v", err)
+	}
+
+	assertColumn(t, matrix.Head(2), "ID", "0", "1")
+	assertColumn(t, matrix.Tail(2), "ID", "3", "4")
+	if matrix.Head(10).LenRows() != 5 || matrix.Tail(-1).LenRows() != 0 {
+		t.Fatalf("expected Head and Tail to clamp n")
+	}
+}
+
+// TestPage tests Page.
+func TestPage(t *testing.T) {
+	matrix := newNumberedMatrix(5)
+
+	page, err := matrix.Page(2, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, page.Matrix, "ID", "2", "3")
+	if page.TotalRows != 5 || page.TotalPages != 3 || !page.HasNext() {
+		t.Fatalf("unexpected page metadata: %+v", page)
+	}
+	last, _ := matrix.Page(3, 2)
+	assertColumn(t, last.Matrix, "ID", "4")
+	if last.HasNext() {
+		t.Fatalf("expected last page to have no next page")
+	}
+	past, _ := matrix.Page(4, 2)
+	if past.Matrix.LenRows() != 0 {
+		t.Fatalf("expected empty page, got %d rows", past.Matrix.LenRows())
+	}
+	if _, err = matrix.Page(0, 2); err == nil {
+		t.Fatalf("expected error for page number 0")
+	}
+
+	// Changing a page leaves the matrix unchanged.
+	page.Matrix.UpdateRowColumn(0, "Group", "changed")
+	assertColumn(t, matrix, "Group", "a", "b", "a", "b", "a")
+}
+
+// TestPageAfter tests cursor-based pagination.
+func TestPageAfter(t *testing.T) {
+	matrix := newNumberedMatrix(5)
+	orderBy := []SortKey{{Column: "ID", Desc: true, Numeric: true}}
+
+	first, err := matrix.PageAfter(orderBy, "", 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, first.Matrix, "ID", "4", "3")
+	first.Matrix.UpdateRowColumn(0, "Group", "changed")
+	if row, _ := matrix.GetRow(4); row[1] != "a" {
+		t.Fatalf("expected changing a page to leave the matrix unchanged, got %v", row)
+	}
+
+	// Rows removed before the cursor do not shift the next page.
+	_ = matrix.DeleteRow(4)
+	second, err := matrix.PageAfter(orderBy, first.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, second.Matrix, "ID", "2", "1")
+
+	third, _ := matrix.PageAfter(orderBy, second.NextCursor, 2)
+	assertColumn(t, third.Matrix, "ID", "0")
+	if third.NextCursor != "" {
+		t.Fatalf("expected no cursor on the last page, got %s", third.NextCursor)
+	}
+
+	if _, err = matrix.PageAfter(orderBy, "not a cursor!", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor error, got %v", err)
+	}
+}
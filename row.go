@@ -0,0 +1,33 @@
+package bdatamatrix
+
+import "fmt"
+
+// Row is a read-only view of a single row of a matrix.
+type Row struct {
+	index       int
+	values      []string
+	headerIndex map[string]int
+}
+
+// Index returns the index of the row in the matrix.
+func (r Row) Index() int {
+	return r.index
+}
+
+// Get returns the value of the row for the given column.
+func (r Row) Get(key string) (string, error) {
+	idx, exists := r.headerIndex[key]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	return r.values[idx], nil
+}
+
+// Values returns a copy of the values of the row in header order.
+func (r Row) Values() []string {
+	return append([]string(nil), r.values...)
+}
+
+func (t *bDataMatrix) row(index int) Row {
+	return Row{index: index, values: t.rows[index], headerIndex: t.headerIndex}
+}
@@ -0,0 +1,158 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+func (t *bDataMatrix) Sample(n int, seed int64) (BDataMatrix, error) {
+	if n < 0 || n > t.LenRows() {
+		return nil, fmt.Errorf("sample size (%d) must be between 0 and the number of rows (%d)", n, t.LenRows())
+	}
+	rng := newRandom(seed)
+	indexes := rng.choose(t.allIndexes(), n)
+	sort.Ints(indexes)
+	return t.view(indexes), nil
+}
+
+func (t *bDataMatrix) SampleFraction(f float64, seed int64) (BDataMatrix, error) {
+	if err := checkFraction(f); err != nil {
+		return nil, err
+	}
+	return t.Sample(fractionSize(f, t.LenRows()), seed)
+}
+
+func (t *bDataMatrix) SampleStratified(key string, f float64, seed int64) (BDataMatrix, error) {
+	idx, exists := t.headerIndex[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	if err := checkFraction(f); err != nil {
+		return nil, err
+	}
+	groups := make(map[string][]int)
+	var order []string
+	for i, row := range t.rows {
+		if _, ok := groups[row[idx]]; !ok {
+			order = append(order, row[idx])
+		}
+		groups[row[idx]] = append(groups[row[idx]], i)
+	}
+	rng := newRandom(seed)
+	var indexes []int
+	for _, value := range order {
+		group := groups[value]
+		indexes = append(indexes, rng.choose(group, fractionSize(f, len(group)))...)
+	}
+	sort.Ints(indexes)
+	return t.view(indexes), nil
+}
+
+func (t *bDataMatrix) Shuffle(seed int64) {
+	order := t.allIndexes()
+	newRandom(seed).shuffle(order)
+	t.reorderRows(order)
+}
+
+func (t *bDataMatrix) Split(ratios []float64, seed int64) ([]BDataMatrix, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("at least one ratio is required")
+	}
+	var total float64
+	for _, r := range ratios {
+		if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+			return nil, fmt.Errorf("ratio %v must be a non-negative number", r)
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("ratios must not all be zero")
+	}
+	order := t.allIndexes()
+	newRandom(seed).shuffle(order)
+
+	parts := make([]BDataMatrix, len(ratios))
+	var cumulative float64
+	start := 0
+	for i, r := range ratios {
+		cumulative += r
+		end := int(math.Round(cumulative / total * float64(t.LenRows())))
+		if i == len(ratios)-1 {
+			end = t.LenRows()
+		}
+		indexes := append([]int(nil), order[start:end]...)
+		sort.Ints(indexes)
+		parts[i] = t.view(indexes)
+		start = end
+	}
+	return parts, nil
+}
+
+// allIndexes returns the indexes of every row, in order.
+func (t *bDataMatrix) allIndexes() []int {
+	indexes := make([]int, t.LenRows())
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func checkFraction(f float64) error {
+	if f < 0 || f > 1 || math.IsNaN(f) {
+		return fmt.Errorf("fraction (%v) must be between 0 and 1", f)
+	}
+	return nil
+}
+
+// fractionSize returns the number of rows making up the fraction f of n rows, rounded to the nearest integer.
+func fractionSize(f float64, n int) int {
+	return int(math.Round(f * float64(n)))
+}
+
+// random is a splitmix64 pseudo-random number generator. It is used instead of math/rand so that
+// sampling and shuffling give the same results for a given seed across Go versions.
+type random struct {
+	state uint64
+}
+
+func newRandom(seed int64) *random {
+	return &random{state: uint64(seed)}
+}
+
+func (r *random) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// intn returns a uniformly distributed number in [0, n). Values that would bias the modulo are rejected.
+func (r *random) intn(n int) int {
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		if v := r.next(); v >= threshold {
+			return int(v % bound)
+		}
+	}
+}
+
+// shuffle permutes values in place with the Fisher-Yates algorithm.
+func (r *random) shuffle(values []int) {
+	for i := len(values) - 1; i > 0; i-- {
+		j := r.intn(i + 1)
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+// choose returns n values picked at random without replacement, leaving values unchanged.
+func (r *random) choose(values []int, n int) []int {
+	picked := append([]int(nil), values...)
+	for i := 0; i < n; i++ {
+		j := i + r.intn(len(picked)-i)
+		picked[i], picked[j] = picked[j], picked[i]
+	}
+	return picked[:n]
+}
@@ -0,0 +1,106 @@
+package bdatamatrix
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRandom tests that the random number generator matches the reference splitmix64 sequence.
+func TestRandom(t *testing.T) {
+	if v := newRandom(0).next(); v != 16294208416658607535 {
+		t.Fatalf("expected 16294208416658607535, got %d", v)
+	}
+}
+
+// TestSample tests Sample, SampleFraction and SampleStratified.
+func TestSample(t *testing.T) {
+	matrix := newNumberedMatrix(10)
+
+	sample, err := matrix.Sample(3, 42)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, sample, "ID", "2", "3", "4")
+	again, _ := matrix.Sample(3, 42)
+	assertColumn(t, again, "ID", "2", "3", "4")
+	if _, err = matrix.Sample(11, 42); err == nil {
+		t.Fatalf("expected error for a sample larger than the matrix")
+	}
+
+	fraction, err := matrix.SampleFraction(0.5, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if fraction.LenRows() != 5 {
+		t.Fatalf("expected 5 rows, got %d", fraction.LenRows())
+	}
+	if _, err = matrix.SampleFraction(1.5, 1); err == nil {
+		t.Fatalf("expected error for a fraction above 1")
+	}
+
+	stratified, err := matrix.SampleStratified("Group", 0.4, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	counts := make(map[string]int)
+	groups, _ := stratified.GetColumn("Group")
+	for _, g := range groups {
+		counts[g]++
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Fatalf("expected 2 rows of each group, got %v", counts)
+	}
+
+	// Changing a sample leaves the matrix unchanged.
+	for _, m := range []BDataMatrix{sample, fraction, stratified} {
+		for i := 0; i < m.LenRows(); i++ {
+			m.UpdateRowColumn(i, "Group", "changed")
+		}
+	}
+	assertColumn(t, matrix, "Group", "a", "b", "a", "b", "a", "b", "a", "b", "a", "b")
+}
+
+// TestShuffle tests that Shuffle is deterministic for a seed.
+func TestShuffle(t *testing.T) {
+	matrix := newNumberedMatrix(10)
+	matrix.SetPrimaryKey("ID")
+	matrix.Shuffle(7)
+	assertColumn(t, matrix, "ID", "8", "1", "5", "9", "0", "4", "3", "2", "6", "7")
+	row, err := matrix.GetByKey("9")
+	if err != nil || row[0] != "9" {
+		t.Fatalf("expected primary key index to follow the shuffle, got %v, %v", row, err)
+	}
+}
+
+// TestSplit tests that Split returns disjoint matrices covering every row.
+func TestSplit(t *testing.T) {
+	matrix := newNumberedMatrix(10)
+	parts, err := matrix.Split([]float64{0.8, 0.2}, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(parts) != 2 || parts[0].LenRows() != 8 || parts[1].LenRows() != 2 {
+		t.Fatalf("expected parts of 8 and 2 rows, got %d parts", len(parts))
+	}
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		ids, _ := part.GetColumn("ID")
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("expected disjoint parts, row %s appears twice", id)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != 10 {
+		t.Fatalf("expected every row to be in a part, got %d", len(seen))
+	}
+	// Changing a part leaves the matrix unchanged.
+	parts[1].UpdateRowColumn(0, "Group", "changed")
+	if groups, _ := matrix.GetColumn("Group"); strings.Contains(strings.Join(groups, ","), "changed") {
+		t.Fatalf("expected changing a part to leave the matrix unchanged, got %v", groups)
+	}
+	if _, err = matrix.Split([]float64{-1, 2}, 3); err == nil {
+		t.Fatalf("expected error for a negative ratio")
+	}
+}
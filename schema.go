@@ -0,0 +1,294 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind defines the inferred or declared type of a column.
+type Kind int
+
+const (
+	KindString Kind = iota + 1
+	KindInt
+	KindFloat
+	KindBool
+	KindTime
+)
+
+func (k Kind) String() string {
+	v, ok := map[Kind]string{
+		KindString: "string",
+		KindInt:    "int",
+		KindFloat:  "float",
+		KindBool:   "bool",
+		KindTime:   "time",
+	}[k]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// DefaultTimeLayouts is the list of candidate layouts used by InferSchema when no layouts are provided.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.DateTime,
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"02/01/2006",
+	"01/02/2006",
+}
+
+// SchemaViolation describes a single cell that does not match the kind of its column.
+type SchemaViolation struct {
+	// Row is the index of the row holding the value.
+	Row int
+	// Value is the offending cell value.
+	Value string
+}
+
+// ColumnSchema describes the kind of a single column.
+//
+// When produced by InferSchema, Sampled, Failed, Confidence and Violations describe how well
+// the sampled values fit the proposed kind. They are ignored by ApplySchema.
+type ColumnSchema struct {
+	// Column is the header name of the column.
+	Column string
+	// Kind is the type of the column values.
+	Kind Kind
+	// Layout is the time layout used when Kind is KindTime.
+	Layout string
+	// Sampled is the number of rows inspected.
+	Sampled int
+	// Failed is the number of non-empty sampled values that could not be parsed as Kind.
+	Failed int
+	// Confidence is the ratio of non-empty sampled values that parsed as Kind, between 0 and 1.
+	Confidence float64
+	// Violations holds up to the first few values that could not be parsed as Kind.
+	Violations []SchemaViolation
+}
+
+// Check reports whether value can be parsed as the kind of the column.
+// Empty values are always accepted.
+func (c ColumnSchema) Check(value string) error {
+	if value == "" || parseKind(c.Kind, c.Layout, value) {
+		return nil
+	}
+	return fmt.Errorf("%w: column '%s' expects %s, got '%s'", ErrSchemaMismatch, c.Column, c.kindName(), value)
+}
+
+func (c ColumnSchema) String() string {
+	s := fmt.Sprintf("column %s: %s", c.Column, c.kindName())
+	if c.Failed == 0 {
+		return s
+	}
+	examples := make([]string, len(c.Violations))
+	for i, v := range c.Violations {
+		examples[i] = fmt.Sprintf("row %d '%s'", v.Row, v.Value)
+	}
+	return fmt.Sprintf("%s, %d of %d rows failed: %s", s, c.Failed, c.Sampled, strings.Join(examples, ", "))
+}
+
+func (c ColumnSchema) kindName() string {
+	if c.Kind == KindTime && c.Layout != "" {
+		return fmt.Sprintf("%s(%s)", c.Kind, c.Layout)
+	}
+	return c.Kind.String()
+}
+
+// Schema describes the kinds of the columns of a matrix.
+type Schema struct {
+	// Columns holds one entry per described column.
+	Columns []ColumnSchema
+}
+
+// Column returns the schema of the column with the given name.
+func (s Schema) Column(key string) (ColumnSchema, bool) {
+	for _, c := range s.Columns {
+		if c.Column == key {
+			return c, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+func (s Schema) String() string {
+	lines := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+const (
+	// inferMinConfidence is the minimal ratio of parsable values for a kind other than KindString to be proposed.
+	inferMinConfidence = 0.9
+	// inferMaxViolations is the maximal number of violations kept per column.
+	inferMaxViolations = 5
+)
+
+func (t *bDataMatrix) InferSchema(sampleSize int, layouts ...string) Schema {
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	n := t.LenRows()
+	if sampleSize > 0 {
+		n = min(n, sampleSize)
+	}
+	schema := Schema{Columns: make([]ColumnSchema, t.LenColumns())}
+	for j, key := range t.header {
+		schema.Columns[j] = t.inferColumn(j, key, n, layouts)
+	}
+	return schema
+}
+
+func (t *bDataMatrix) inferColumn(idx int, key string, n int, layouts []string) ColumnSchema {
+	// Candidates are ordered from the most to the least specific kind, so ties favor the more specific one.
+	candidates := []ColumnSchema{{Kind: KindInt}, {Kind: KindFloat}, {Kind: KindBool}}
+	for _, layout := range layouts {
+		candidates = append(candidates, ColumnSchema{Kind: KindTime, Layout: layout})
+	}
+	matched := make([]int, len(candidates))
+	nonEmpty := 0
+	for i := 0; i < n; i++ {
+		value := t.rows[i][idx]
+		if value == "" {
+			continue
+		}
+		nonEmpty++
+		for c, candidate := range candidates {
+			if parseKind(candidate.Kind, candidate.Layout, value) {
+				matched[c]++
+			}
+		}
+	}
+
+	result := ColumnSchema{Column: key, Kind: KindString, Sampled: n, Confidence: 1}
+	if nonEmpty == 0 {
+		return result
+	}
+	best := 0
+	for c := range candidates {
+		if matched[c] > matched[best] {
+			best = c
+		}
+	}
+	confidence := float64(matched[best]) / float64(nonEmpty)
+	if confidence < inferMinConfidence {
+		return result
+	}
+	result.Kind = candidates[best].Kind
+	result.Layout = candidates[best].Layout
+	result.Failed = nonEmpty - matched[best]
+	result.Confidence = confidence
+	for i := 0; i < n && len(result.Violations) < inferMaxViolations; i++ {
+		if err := result.Check(t.rows[i][idx]); err != nil {
+			result.Violations = append(result.Violations, SchemaViolation{Row: i, Value: t.rows[i][idx]})
+		}
+	}
+	return result
+}
+
+func (t *bDataMatrix) ApplySchema(schema Schema) error {
+	for _, c := range schema.Columns {
+		idx, exists := t.headerIndex[c.Column]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, c.Column)
+		}
+		if c.Kind.String() == "unknown" {
+			return fmt.Errorf("%w: column '%s' has no valid kind", ErrSchemaMismatch, c.Column)
+		}
+		for i, row := range t.rows {
+			if err := c.Check(row[idx]); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+	}
+	applied := Schema{Columns: make([]ColumnSchema, len(schema.Columns))}
+	for i, c := range schema.Columns {
+		// Aliases are resolved so that the schema always names header columns.
+		column := t.header[t.headerIndex[c.Column]]
+		applied.Columns[i] = ColumnSchema{Column: column, Kind: c.Kind, Layout: c.Layout}
+	}
+	t.schema = &applied
+	return nil
+}
+
+func (t *bDataMatrix) Schema() (Schema, bool) {
+	if t.schema == nil {
+		return Schema{}, false
+	}
+	return Schema{Columns: append([]ColumnSchema(nil), t.schema.Columns...)}, true
+}
+
+// checkSchema validates a full row against the applied schema, if any.
+func (t *bDataMatrix) checkSchema(values []string) error {
+	if t.schema == nil {
+		return nil
+	}
+	for _, c := range t.schema.Columns {
+		idx, exists := t.headerIndex[c.Column]
+		if !exists {
+			continue
+		}
+		if err := c.Check(values[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchemaValue validates a single cell against the applied schema, if any.
+func (t *bDataMatrix) checkSchemaValue(key, value string) error {
+	if t.schema == nil {
+		return nil
+	}
+	if idx, exists := t.headerIndex[key]; exists {
+		key = t.header[idx]
+	}
+	c, ok := t.schema.Column(key)
+	if !ok {
+		return nil
+	}
+	return c.Check(value)
+}
+
+// pruneSchema drops the schema entries of columns that no longer exist.
+func (t *bDataMatrix) pruneSchema() {
+	if t.schema == nil {
+		return
+	}
+	columns := t.schema.Columns[:0]
+	for _, c := range t.schema.Columns {
+		if _, exists := t.headerIndex[c.Column]; exists {
+			columns = append(columns, c)
+		}
+	}
+	t.schema.Columns = columns
+}
+
+func parseKind(kind Kind, layout, value string) bool {
+	switch kind {
+	case KindString:
+		return true
+	case KindInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case KindFloat:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case KindBool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case KindTime:
+		_, err := time.Parse(layout, value)
+		return err == nil
+	default:
+		return false
+	}
+}
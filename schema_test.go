@@ -0,0 +1,139 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestInferSchema tests InferSchema.
+func TestInferSchema(t *testing.T) {
+	matrix, _ := New("ID", "Price", "Active", "Created", "Name", "Age")
+	matrix.AddRow("1", "9.99", "true", "2024-01-02", "Alice", "30")
+	matrix.AddRow("2", "10", "false", "2024-02-03", "Bob", "N/A")
+	for i := 0; i < 18; i++ {
+		matrix.AddRow("3", "1.5", "true", "2024-03-04", "Carol", "41")
+	}
+	schema := matrix.InferSchema(0)
+	expected := map[string]Kind{
+		"ID":      KindInt,
+		"Price":   KindFloat,
+		"Active":  KindBool,
+		"Created": KindTime,
+		"Name":    KindString,
+		"Age":     KindInt,
+	}
+	for key, kind := range expected {
+		c, ok := schema.Column(key)
+		if !ok {
+			t.Fatalf("expected schema for column %s", key)
+		}
+		if c.Kind != kind {
+			t.Fatalf("expected column %s to be %s, got %s", key, kind, c.Kind)
+		}
+	}
+	created, _ := schema.Column("Created")
+	if created.Layout != "2006-01-02" {
+		t.Fatalf("expected layout 2006-01-02, got %s", created.Layout)
+	}
+	age, _ := schema.Column("Age")
+	if age.Failed != 1 || len(age.Violations) != 1 || age.Violations[0].Row != 1 {
+		t.Fatalf("expected one violation at row 1, got %+v", age)
+	}
+	if got := age.String(); got != "column Age: int, 1 of 20 rows failed: row 1 'N/A'" {
+		t.Fatalf("unexpected column description: %s", got)
+	}
+
+	// Test sample size.
+	schema = matrix.InferSchema(1)
+	age, _ = schema.Column("Age")
+	if age.Sampled != 1 || age.Failed != 0 {
+		t.Fatalf("expected 1 sampled row without failure, got %+v", age)
+	}
+
+	// Test custom layouts.
+	schema = matrix.InferSchema(0, "2006/01/02")
+	created, _ = schema.Column("Created")
+	if created.Kind != KindString {
+		t.Fatalf("expected column Created to be string, got %s", created.Kind)
+	}
+}
+
+// TestApplySchema tests ApplySchema and the checks it enables.
+func TestApplySchema(t *testing.T) {
+	matrix, _ := New("ID", "Age")
+	matrix.AddRow("1", "30")
+	matrix.AddRow("2", "N/A")
+	schema := Schema{Columns: []ColumnSchema{
+		{Column: "ID", Kind: KindInt},
+		{Column: "Age", Kind: KindInt, Failed: 1, Violations: []SchemaViolation{{Row: 1, Value: "N/A"}}},
+	}}
+	err := matrix.ApplySchema(schema)
+	if !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error, got %v", err)
+	}
+	if _, ok := matrix.Schema(); ok {
+		t.Fatal("expected no schema after a failed apply")
+	}
+
+	matrix.UpdateRowColumn(1, "Age", "")
+	if err = matrix.ApplySchema(schema); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	applied, ok := matrix.Schema()
+	if !ok || len(applied.Columns) != 2 {
+		t.Fatalf("expected applied schema with 2 columns, got %+v", applied)
+	}
+	if applied.Columns[1].Violations != nil {
+		t.Fatal("expected inference details to be dropped")
+	}
+
+	if err = matrix.AddRow("3", "abc"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error on AddRow, got %v", err)
+	}
+	if err = matrix.UpdateRow(0, "x", "1"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error on UpdateRow, got %v", err)
+	}
+	if err = matrix.UpdateRowColumn(0, "Age", "1.5"); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch error on UpdateRowColumn, got %v", err)
+	}
+	if err = matrix.AddRow("3", "42"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Test the schema follows copies and column deletion.
+	copied := matrix.Copy()
+	if _, ok = copied.Schema(); !ok {
+		t.Fatal("expected copy to keep the schema")
+	}
+	matrix.DeleteColumn("Age")
+	applied, _ = matrix.Schema()
+	if _, ok = applied.Column("Age"); ok {
+		t.Fatal("expected deleted column to be removed from the schema")
+	}
+
+	// Test unknown column.
+	err = matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "Gender", Kind: KindString}}})
+	if !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+
+	// Test a column without a kind.
+	err = matrix.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "ID"}}})
+	if !errors.Is(err, ErrSchemaMismatch) || !strings.Contains(err.Error(), "no valid kind") {
+		t.Fatalf("expected schema mismatch error for a missing kind, got %v", err)
+	}
+}
+
+func TestSchemaString(t *testing.T) {
+	schema := Schema{Columns: []ColumnSchema{
+		{Column: "ID", Kind: KindInt},
+		{Column: "Created", Kind: KindTime, Layout: "2006-01-02"},
+	}}
+	if got := schema.String(); !strings.Contains(got, "column ID: int") || !strings.Contains(got, "time(2006-01-02)") {
+		t.Fatalf("unexpected schema description: %s", got)
+	}
+	if Kind(0).String() != "unknown" {
+		t.Fatal("expected unknown kind name")
+	}
+}
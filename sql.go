@@ -0,0 +1,310 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSQLBatchSize is the number of rows per INSERT statement when SQLOptions.BatchSize is zero.
+const DefaultSQLBatchSize = 100
+
+// SQLDialect defines the SQL flavor used for identifier quoting, literal escaping and column types.
+type SQLDialect int
+
+const (
+	// DialectPostgres is the dialect of PostgreSQL.
+	DialectPostgres SQLDialect = iota + 1
+	// DialectMySQL is the dialect of MySQL and MariaDB.
+	DialectMySQL
+	// DialectSQLite is the dialect of SQLite.
+	DialectSQLite
+)
+
+func (d SQLDialect) String() string {
+	v, ok := map[SQLDialect]string{
+		DialectPostgres: "postgres",
+		DialectMySQL:    "mysql",
+		DialectSQLite:   "sqlite",
+	}[d]
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
+
+// SQLOptions specifies the SQL script generated by ToSQL.
+type SQLOptions struct {
+	// Dialect is the SQL dialect. The zero value means DialectPostgres.
+	Dialect SQLDialect
+	// BatchSize is the maximum number of rows per INSERT statement. The zero value means
+	// DefaultSQLBatchSize.
+	BatchSize int
+	// OmitCreateTable indicates whether the CREATE TABLE statement is left out.
+	OmitCreateTable bool
+	// IfNotExists indicates whether the table is only created when it does not exist yet.
+	IfNotExists bool
+	// Upsert indicates whether rows whose primary key already exists in the table are updated
+	// instead of failing the insert. It requires a primary key.
+	Upsert bool
+}
+
+func (t *bDataMatrix) ToSQL(table string, opts SQLOptions) StreamingOutput {
+	opts, err := t.sqlOptions(opts)
+	if err != nil {
+		return &outputData{stream: errorStream(err)}
+	}
+	return &outputData{stream: t.snapshot().sqlStream(table, opts)}
+}
+
+func (t *bDataMatrix) WriteSQL(w io.Writer, table string, opts SQLOptions) error {
+	opts, err := t.sqlOptions(opts)
+	if err != nil {
+		return err
+	}
+	return runStream(t.sqlStream(table, opts), w)
+}
+
+// sqlOptions validates opts and fills in its defaults.
+func (t *bDataMatrix) sqlOptions(opts SQLOptions) (SQLOptions, error) {
+	if opts.Dialect == 0 {
+		opts.Dialect = DialectPostgres
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultSQLBatchSize
+	}
+	if opts.Dialect < DialectPostgres || opts.Dialect > DialectSQLite {
+		return opts, fmt.Errorf("unknown SQL dialect: %v", opts.Dialect)
+	}
+	if opts.Upsert && len(t.primaryKey) == 0 {
+		return opts, fmt.Errorf("%w: upsert requires a primary key", ErrNoPrimaryKey)
+	}
+	return opts, nil
+}
+
+// sqlColumns returns the schema of every column in header order, with KindString for the columns
+// the applied schema does not describe.
+func (t *bDataMatrix) sqlColumns() []ColumnSchema {
+	columns := make([]ColumnSchema, t.LenColumns())
+	for j, key := range t.header {
+		columns[j] = ColumnSchema{Column: key, Kind: KindString}
+		if t.schema != nil {
+			if c, ok := t.schema.Column(key); ok {
+				columns[j] = c
+			}
+		}
+	}
+	return columns
+}
+
+// insertInto returns the beginning of the INSERT statements into table, up to the VALUES keyword.
+func (t *bDataMatrix) insertInto(d SQLDialect, table string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES", d.quoteIdent(table), d.quoteIdents(t.header))
+}
+
+// sqlStream exports the matrix as a CREATE TABLE statement followed by batched INSERT statements.
+func (t *bDataMatrix) sqlStream(table string, opts SQLOptions) stream {
+	d := opts.Dialect
+	return func(w io.Writer) func() error {
+		var sb strings.Builder
+		var insert, conflict string
+		var kinds []ColumnSchema
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				kinds = t.sqlColumns()
+				insert = t.insertInto(d, table) + "\n"
+				conflict = t.upsertClause(d)
+				if !opts.OmitCreateTable {
+					t.writeCreateTable(&sb, table, kinds, opts)
+				}
+				return nil
+			},
+			item: func(i int) error {
+				if i%opts.BatchSize == 0 {
+					sb.WriteString(insert)
+				} else {
+					sb.WriteString(",\n")
+				}
+				sb.WriteString("  (")
+				for j, value := range t.rows[i] {
+					if j > 0 {
+						sb.WriteString(", ")
+					}
+					if t.isNullAt(i, j) {
+						sb.WriteString("NULL")
+						continue
+					}
+					sb.WriteString(d.literal(kinds[j], value))
+				}
+				sb.WriteString(")")
+				if (i+1)%opts.BatchSize == 0 || i == t.LenRows()-1 {
+					if opts.Upsert {
+						sb.WriteString("\n" + conflict)
+					}
+					sb.WriteString(";\n")
+				}
+				return nil
+			},
+			flush: func() error {
+				_, err := io.WriteString(w, sb.String())
+				sb.Reset()
+				return err
+			},
+		}.next()
+	}
+}
+
+// writeCreateTable writes the CREATE TABLE statement of the matrix, using the kinds of the schema.
+func (t *bDataMatrix) writeCreateTable(sb *strings.Builder, table string, kinds []ColumnSchema, opts SQLOptions) {
+	d := opts.Dialect
+	sb.WriteString("CREATE TABLE ")
+	if opts.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	sb.WriteString(d.quoteIdent(table) + " (\n")
+	for j, key := range t.header {
+		isKey := t.isKeyColumn(key)
+		sb.WriteString("  " + d.quoteIdent(key) + " " + d.columnType(kinds[j].Kind, isKey))
+		if isKey {
+			sb.WriteString(" NOT NULL")
+		}
+		if j < t.LenColumns()-1 || len(t.primaryKey) > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	if len(t.primaryKey) > 0 {
+		sb.WriteString("  PRIMARY KEY (" + d.quoteIdents(t.primaryKey) + ")\n")
+	}
+	sb.WriteString(");\n")
+}
+
+// upsertClause returns the clause updating the non-key columns of rows whose primary key exists.
+func (t *bDataMatrix) upsertClause(d SQLDialect) string {
+	var updates []string
+	for _, key := range t.header {
+		if t.isKeyColumn(key) {
+			continue
+		}
+		q := d.quoteIdent(key)
+		if d == DialectMySQL {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", q, q))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", q, q))
+		}
+	}
+	if d == DialectMySQL {
+		if len(updates) == 0 {
+			// Every column is part of the key, so there is nothing to update.
+			q := d.quoteIdent(t.primaryKey[0])
+			updates = append(updates, fmt.Sprintf("%s = %s", q, q))
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+	clause := "ON CONFLICT (" + d.quoteIdents(t.primaryKey) + ") "
+	if len(updates) == 0 {
+		return clause + "DO NOTHING"
+	}
+	return clause + "DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// quoteIdent quotes an identifier, doubling the quote characters inside it.
+func (d SQLDialect) quoteIdent(name string) string {
+	if d == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d SQLDialect) quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteString quotes a string literal. MySQL treats backslashes as escapes by default, so they are
+// escaped as well.
+func (d SQLDialect) quoteString(s string) string {
+	if d == DialectMySQL {
+		s = strings.NewReplacer(`\`, `\\`, "'", "''", "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`).Replace(s)
+		return "'" + s + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// columnType returns the column type of a kind. MySQL cannot index TEXT columns without a length,
+// so its key columns use VARCHAR.
+func (d SQLDialect) columnType(kind Kind, isKey bool) string {
+	switch kind {
+	case KindInt:
+		if d == DialectSQLite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case KindFloat:
+		switch d {
+		case DialectPostgres:
+			return "DOUBLE PRECISION"
+		case DialectMySQL:
+			return "DOUBLE"
+		default:
+			return "REAL"
+		}
+	case KindBool:
+		return "BOOLEAN"
+	case KindTime:
+		if d == DialectPostgres {
+			return "TIMESTAMP"
+		}
+		return "DATETIME"
+	default:
+		if d == DialectMySQL && isKey {
+			return "VARCHAR(255)"
+		}
+		return "TEXT"
+	}
+}
+
+// literal returns the SQL literal of a value of a column. Empty values of columns that are not
+// strings are NULL, and values that do not parse as the kind of their column are quoted strings.
+func (d SQLDialect) literal(c ColumnSchema, value string) string {
+	if c.Kind != KindString && value == "" {
+		return "NULL"
+	}
+	switch c.Kind {
+	case KindInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value
+		}
+	case KindFloat:
+		// Infinities and NaN have no literal, so they are kept as strings.
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return formatFloat(f)
+		}
+	case KindBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			switch {
+			case d == DialectSQLite && b:
+				return "1"
+			case d == DialectSQLite:
+				return "0"
+			case b:
+				return "TRUE"
+			default:
+				return "FALSE"
+			}
+		}
+	case KindTime:
+		// TIMESTAMP and DATETIME columns hold no time zone, so times are written in UTC.
+		if tm, err := time.Parse(c.Layout, value); err == nil {
+			return d.quoteString(tm.UTC().Format("2006-01-02 15:04:05.999999"))
+		}
+	}
+	return d.quoteString(value)
+}
@@ -0,0 +1,127 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestToSQL tests ToSQL in every dialect.
+func TestToSQL(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "O'Brien", "2.50", "true", "2024-01-02"},
+		{"2", `back\slash`, "", "false", "2024-03-04"},
+	}, "ID", "Name", "Score", "Active", "Joined")
+	matrix.ApplySchema(Schema{Columns: []ColumnSchema{
+		{Column: "ID", Kind: KindInt},
+		{Column: "Score", Kind: KindFloat},
+		{Column: "Active", Kind: KindBool},
+		{Column: "Joined", Kind: KindTime, Layout: time.DateOnly},
+	}})
+	matrix.SetPrimaryKey("ID")
+	matrix.SetNull(1, "Active")
+
+	got := matrix.ToSQL("people", SQLOptions{}).String()
+	expected := `CREATE TABLE "people" (
+  "ID" BIGINT NOT NULL,
+  "Name" TEXT,
+  "Score" DOUBLE PRECISION,
+  "Active" BOOLEAN,
+  "Joined" TIMESTAMP,
+  PRIMARY KEY ("ID")
+);
+INSERT INTO "people" ("ID", "Name", "Score", "Active", "Joined") VALUES
+  (1, 'O''Brien', 2.5, TRUE, '2024-01-02 00:00:00'),
+  (2, 'back\slash', NULL, NULL, '2024-03-04 00:00:00');
+`
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	got = matrix.ToSQL("people", SQLOptions{Dialect: DialectMySQL, IfNotExists: true}).String()
+	expected = "CREATE TABLE IF NOT EXISTS `people` (\n" +
+		"  `ID` BIGINT NOT NULL,\n  `Name` TEXT,\n  `Score` DOUBLE,\n  `Active` BOOLEAN,\n  `Joined` DATETIME,\n" +
+		"  PRIMARY KEY (`ID`)\n);\n" +
+		"INSERT INTO `people` (`ID`, `Name`, `Score`, `Active`, `Joined`) VALUES\n" +
+		"  (1, 'O''Brien', 2.5, TRUE, '2024-01-02 00:00:00'),\n" +
+		"  (2, 'back\\\\slash', NULL, NULL, '2024-03-04 00:00:00');\n"
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	zoned, _ := NewWithData([][]string{{"2024-01-02T10:30:00+07:00"}}, "At")
+	zoned.ApplySchema(Schema{Columns: []ColumnSchema{{Column: "At", Kind: KindTime, Layout: time.RFC3339}}})
+	got = zoned.ToSQL("t", SQLOptions{OmitCreateTable: true}).String()
+	if expected = "INSERT INTO \"t\" (\"At\") VALUES\n  ('2024-01-02 03:30:00');\n"; got != expected {
+		t.Fatalf("expected times in UTC %s, got %s", expected, got)
+	}
+
+	got = matrix.ToSQL("people", SQLOptions{Dialect: DialectSQLite, OmitCreateTable: true}).String()
+	expected = `INSERT INTO "people" ("ID", "Name", "Score", "Active", "Joined") VALUES
+  (1, 'O''Brien', 2.5, 1, '2024-01-02 00:00:00'),
+  (2, 'back\slash', NULL, NULL, '2024-03-04 00:00:00');
+`
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+// TestToSQLBatchesAndUpsert tests ToSQL batching and upsert clauses.
+func TestToSQLBatchesAndUpsert(t *testing.T) {
+	matrix := newNumberedMatrix(5)
+	matrix.SetPrimaryKey("ID")
+
+	got := matrix.ToSQL("t", SQLOptions{BatchSize: 2, OmitCreateTable: true, Upsert: true}).String()
+	if n := strings.Count(got, "INSERT INTO"); n != 3 {
+		t.Fatalf("expected 3 INSERT statements, got %d:\n%s", n, got)
+	}
+	if n := strings.Count(got, `ON CONFLICT ("ID") DO UPDATE SET "Group" = excluded."Group";`); n != 3 {
+		t.Fatalf("expected 3 upsert clauses, got %d:\n%s", n, got)
+	}
+
+	got = matrix.ToSQL("t", SQLOptions{Dialect: DialectMySQL, OmitCreateTable: true, Upsert: true}).String()
+	if !strings.HasSuffix(got, "\nON DUPLICATE KEY UPDATE `Group` = VALUES(`Group`);\n") {
+		t.Fatalf("expected MySQL upsert clause, got %s", got)
+	}
+	create := matrix.ToSQL("t", SQLOptions{Dialect: DialectMySQL}).String()
+	if !strings.Contains(create, "`ID` VARCHAR(255) NOT NULL,") {
+		t.Fatalf("expected MySQL key column to be VARCHAR, got %s", create)
+	}
+
+	matrix.SetPrimaryKey()
+	err := matrix.ToSQL("t", SQLOptions{Upsert: true}).Err()
+	if !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expected ErrNoPrimaryKey, got %v", err)
+	}
+	if err = matrix.ToSQL("t", SQLOptions{Dialect: 9}).Err(); err == nil {
+		t.Fatal("expected error for unknown dialect")
+	}
+}
+
+// TestSQLQuoting tests identifier quoting and literal escaping of every dialect.
+func TestSQLQuoting(t *testing.T) {
+	if got := DialectPostgres.quoteIdent(`a"b`); got != `"a""b"` {
+		t.Fatalf("unexpected identifier %s", got)
+	}
+	if got := DialectMySQL.quoteIdent("a`b"); got != "`a``b`" {
+		t.Fatalf("unexpected identifier %s", got)
+	}
+	if got := DialectMySQL.quoteString("it's\n\x00\\"); got != `'it''s\n\0\\'` {
+		t.Fatalf("unexpected literal %s", got)
+	}
+	if got := DialectSQLite.quoteString("it's\n\\"); got != "'it''s\n\\'" {
+		t.Fatalf("unexpected literal %s", got)
+	}
+	for value, expected := range map[string]string{"12": "12", "abc": "'abc'", "": "NULL"} {
+		if got := DialectPostgres.literal(ColumnSchema{Kind: KindInt}, value); got != expected {
+			t.Fatalf("expected %q to be %s, got %s", value, expected, got)
+		}
+	}
+	if got := DialectPostgres.literal(ColumnSchema{Kind: KindFloat}, "NaN"); got != "'NaN'" {
+		t.Fatalf("expected NaN to be quoted, got %s", got)
+	}
+	if got := DialectPostgres.literal(ColumnSchema{Kind: KindString}, ""); got != "''" {
+		t.Fatalf("expected empty string literal, got %s", got)
+	}
+}
@@ -0,0 +1,220 @@
+package bdatamatrix
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromSQLRows imports the result of a query. The header is built from the column names of rows,
+// with empty and repeated names made unique, and every row is scanned until rows is exhausted.
+//
+// NULL values become null cells. []byte values are stored as strings, numbers in their shortest
+// decimal form, booleans as "true" or "false" and times in time.RFC3339Nano format, so the result
+// can be typed with InferSchema. The caller remains responsible for closing rows.
+//
+// Example usage:
+//
+//	rows, err := db.QueryContext(ctx, "SELECT id, name, created_at FROM users")
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer rows.Close()
+//	matrix, err := FromSQLRows(rows)
+func FromSQLRows(rows *sql.Rows) (BDataMatrix, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, ErrEmptyHeader
+	}
+	nm, err := New(uniqueHeader(columns, func(i int) string {
+		return "column" + strconv.Itoa(i+1)
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	m := nm.(*bDataMatrix)
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for j := range values {
+		dest[j] = &values[j]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("row %d: %w", m.LenRows(), err)
+		}
+		row := make(map[string]string, len(columns))
+		for j, value := range values {
+			if value != nil {
+				row[m.header[j]] = sqlValueString(value)
+			}
+		}
+		m.addValues(row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// sqlValueString formats a value scanned from a database.
+func sqlValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return formatFloat(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (t *bDataMatrix) WriteToSQL(ctx context.Context, db *sql.DB, table string, opts SQLOptions) (err error) {
+	opts, err = t.sqlOptions(opts)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var stmt *sql.Stmt
+	defer func() {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	d := opts.Dialect
+	if !opts.OmitCreateTable {
+		var sb strings.Builder
+		t.writeCreateTable(&sb, table, t.sqlColumns(), opts)
+		if _, err = tx.ExecContext(ctx, strings.TrimSuffix(sb.String(), ";\n")); err != nil {
+			return fmt.Errorf("create table: %w", err)
+		}
+	}
+	kinds := t.sqlColumns()
+	batchSize := min(opts.BatchSize, max(1, d.maxParams()/max(1, t.LenColumns())))
+	prepared := 0
+	for start := 0; start < t.LenRows(); start += batchSize {
+		n := min(batchSize, t.LenRows()-start)
+		// Every batch but the last has the same size, so at most two statements are prepared.
+		if n != prepared {
+			if stmt != nil {
+				_ = stmt.Close()
+			}
+			if stmt, err = tx.PrepareContext(ctx, t.insertStatement(table, n, opts)); err != nil {
+				return err
+			}
+			prepared = n
+		}
+		args := make([]any, 0, n*t.LenColumns())
+		for i := start; i < start+n; i++ {
+			for j, value := range t.rows[i] {
+				if t.isNullAt(i, j) {
+					args = append(args, nil)
+					continue
+				}
+				args = append(args, d.arg(kinds[j], value))
+			}
+		}
+		if _, err = stmt.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("rows %d to %d: %w", start, start+n-1, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// maxParams returns the maximum number of bind parameters of a statement. SQLite allows 32766
+// since version 3.32.0, but only 999 before.
+func (d SQLDialect) maxParams() int {
+	if d == DialectSQLite {
+		return 999
+	}
+	return 65535
+}
+
+// insertStatement returns the statement inserting n rows into table, with one placeholder per cell.
+func (t *bDataMatrix) insertStatement(table string, n int, opts SQLOptions) string {
+	d := opts.Dialect
+	var sb strings.Builder
+	sb.WriteString(t.insertInto(d, table))
+	param := 0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(" (")
+		for j := 0; j < t.LenColumns(); j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			param++
+			sb.WriteString(d.placeholder(param))
+		}
+		sb.WriteString(")")
+	}
+	if opts.Upsert {
+		sb.WriteString(" " + t.upsertClause(d))
+	}
+	return sb.String()
+}
+
+// placeholder returns the placeholder of the nth parameter of a statement, starting from 1.
+func (d SQLDialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// arg returns the statement argument of a value of a column, following the same rules as literal.
+func (d SQLDialect) arg(c ColumnSchema, value string) any {
+	if c.Kind != KindString && value == "" {
+		return nil
+	}
+	switch c.Kind {
+	case KindInt:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case KindFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(v, 0) && !math.IsNaN(v) {
+			return v
+		}
+	case KindBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	case KindTime:
+		if v, err := time.Parse(c.Layout, value); err == nil {
+			return v
+		}
+	}
+	return value
+}
@@ -0,0 +1,221 @@
+package bdatamatrix
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDB is an in-memory database/sql driver that returns fixed rows to every query and records
+// every executed statement.
+type fakeDB struct {
+	columns   []string
+	rows      [][]driver.Value
+	failOn    string
+	prepares  []string
+	execs     []fakeExec
+	commits   int
+	rollbacks int
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.prepares = append(c.db.prepares, query)
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.db.failOn != "" && strings.Contains(s.query, s.db.failOn) {
+		return nil, errors.New("exec failed")
+	}
+	s.db.execs = append(s.db.execs, fakeExec{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{db: s.db}, nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx *fakeTx) Commit() error   { tx.db.commits++; return nil }
+func (tx *fakeTx) Rollback() error { tx.db.rollbacks++; return nil }
+
+type fakeRows struct {
+	db *fakeDB
+	i  int
+}
+
+func (r *fakeRows) Columns() []string { return r.db.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i == len(r.db.rows) {
+		return io.EOF
+	}
+	copy(dest, r.db.rows[r.i])
+	r.i++
+	return nil
+}
+
+// TestFromSQLRows tests FromSQLRows with NULLs, repeated columns and every driver value type.
+func TestFromSQLRows(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+	db := sql.OpenDB(&fakeDB{
+		columns: []string{"id", "name", "name", "score", "ok", "at", ""},
+		rows: [][]driver.Value{
+			{int64(1), "Tom", []byte("T"), 2.5, true, at, nil},
+			{int64(2), nil, []byte{}, float64(1e21), false, nil, "x"},
+		},
+	})
+	defer db.Close()
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	matrix, err := FromSQLRows(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertHeader(t, matrix, "id", "name", "name_2", "score", "ok", "at", "column7")
+	expected := [][]string{
+		{"1", "Tom", "T", "2.5", "true", "2024-01-02T03:04:05.0000006Z", ""},
+		{"2", "", "", "1000000000000000000000", "false", "", "x"},
+	}
+	if got := matrix.Data(false); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for _, cell := range []struct {
+		row  int
+		key  string
+		null bool
+	}{{0, "column7", true}, {1, "name", true}, {1, "at", true}, {1, "name_2", false}} {
+		if null, _ := matrix.IsNull(cell.row, cell.key); null != cell.null {
+			t.Fatalf("expected row %d column %s null to be %v", cell.row, cell.key, cell.null)
+		}
+	}
+}
+
+// TestWriteToSQL tests WriteToSQL batching, typed arguments and the transaction.
+func TestWriteToSQL(t *testing.T) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	matrix := newNumberedMatrix(5)
+	if err := matrix.WriteToSQL(context.Background(), db, "t", SQLOptions{BatchSize: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.commits != 1 || fake.rollbacks != 0 {
+		t.Fatalf("expected a single commit, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+	}
+	if len(fake.execs) != 4 || !strings.HasPrefix(fake.execs[0].query, `CREATE TABLE "t"`) {
+		t.Fatalf("expected CREATE TABLE and 3 inserts, got %v", fake.execs)
+	}
+	if q := fake.execs[1].query; q != `INSERT INTO "t" ("ID", "Group") VALUES ($1, $2), ($3, $4)` {
+		t.Fatalf("unexpected insert statement %s", q)
+	}
+	if q := fake.execs[3].query; q != `INSERT INTO "t" ("ID", "Group") VALUES ($1, $2)` {
+		t.Fatalf("unexpected last insert statement %s", q)
+	}
+	if args := fake.execs[2].args; !reflect.DeepEqual(args, []driver.Value{"2", "a", "3", "b"}) {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+	// The CREATE TABLE statement and the two batch sizes.
+	if len(fake.prepares) != 3 {
+		t.Fatalf("expected 3 prepared statements, got %v", fake.prepares)
+	}
+
+	fake.execs = nil
+	typed, _ := NewWithData([][]string{
+		{"1", "O'Brien", "2.50", "true", "2024-01-02"},
+		{"2", `back\slash`, "", "false", "2024-03-04"},
+	}, "ID", "Name", "Score", "Active", "Joined")
+	typed.ApplySchema(Schema{Columns: []ColumnSchema{
+		{Column: "ID", Kind: KindInt},
+		{Column: "Score", Kind: KindFloat},
+		{Column: "Active", Kind: KindBool},
+		{Column: "Joined", Kind: KindTime, Layout: time.DateOnly},
+	}})
+	typed.SetPrimaryKey("ID")
+	typed.SetNull(1, "Active")
+	err := typed.WriteToSQL(context.Background(), db, "people", SQLOptions{Dialect: DialectMySQL, OmitCreateTable: true, Upsert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exec := fake.execs[0]
+	if !strings.HasSuffix(exec.query, "VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `Name` = VALUES(`Name`), `Score` = VALUES(`Score`), `Active` = VALUES(`Active`), `Joined` = VALUES(`Joined`)") {
+		t.Fatalf("unexpected insert statement %s", exec.query)
+	}
+	expected := []driver.Value{
+		int64(1), "O'Brien", 2.5, true, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		int64(2), `back\slash`, nil, nil, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(exec.args, expected) {
+		t.Fatalf("expected arguments %v, got %v", expected, exec.args)
+	}
+}
+
+// TestWriteToSQLParamLimit tests that batches stay within the bind parameter limit of the dialect.
+func TestWriteToSQLParamLimit(t *testing.T) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	matrix := newNumberedMatrix(600)
+	opts := SQLOptions{Dialect: DialectSQLite, BatchSize: 1000, OmitCreateTable: true}
+	if err := matrix.WriteToSQL(context.Background(), db, "t", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 999 parameters hold 499 rows of 2 columns.
+	if len(fake.execs) != 2 || len(fake.execs[0].args) != 998 || len(fake.execs[1].args) != 202 {
+		t.Fatalf("expected batches of 499 and 101 rows, got %d statements", len(fake.execs))
+	}
+}
+
+// TestWriteToSQLRollback tests that WriteToSQL rolls back when a statement fails.
+func TestWriteToSQLRollback(t *testing.T) {
+	fake := &fakeDB{failOn: "INSERT"}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	err := newNumberedMatrix(3).WriteToSQL(context.Background(), db, "t", SQLOptions{})
+	if err == nil || !strings.Contains(err.Error(), "rows 0 to 2") {
+		t.Fatalf("expected insert error, got %v", err)
+	}
+	if fake.commits != 0 || fake.rollbacks != 1 {
+		t.Fatalf("expected a rollback, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+	}
+
+	err = newNumberedMatrix(3).WriteToSQL(context.Background(), db, "t", SQLOptions{Upsert: true})
+	if !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expected ErrNoPrimaryKey, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TransformFunc defines a transformation applied to a single cell value by Apply and ApplyAll.
+type TransformFunc func(value string) (string, error)
+
+// CellError describes a failure to transform or write a single cell.
+type CellError struct {
+	// Row is the index of the row.
+	Row int
+	// Column is the header name of the column.
+	Column string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("row %d, column %s: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+func (t *bDataMatrix) Apply(key string, fn TransformFunc) error {
+	if _, exists := t.headerIndex[key]; !exists {
+		return fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	return t.apply([]string{key}, fn)
+}
+
+func (t *bDataMatrix) ApplyAll(fn TransformFunc) error {
+	return t.apply(append([]string(nil), t.header...), fn)
+}
+
+// apply transforms every non-null cell of the given columns. Cells that fail keep their value and are reported.
+func (t *bDataMatrix) apply(keys []string, fn TransformFunc) error {
+	var errs []error
+	for i := range t.rows {
+		for _, key := range keys {
+			idx := t.headerIndex[key]
+			if t.isNullAt(i, idx) {
+				continue
+			}
+			value, err := fn(t.rows[i][idx])
+			if err == nil {
+				err = t.UpdateRowColumn(i, key, value)
+			}
+			if err != nil {
+				errs = append(errs, &CellError{Row: i, Column: key, Err: err})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Chain combines transformations into one that applies them in order.
+func Chain(fns ...TransformFunc) TransformFunc {
+	return func(value string) (string, error) {
+		var err error
+		for _, fn := range fns {
+			if value, err = fn(value); err != nil {
+				return "", err
+			}
+		}
+		return value, nil
+	}
+}
+
+// Trim removes leading and trailing whitespace.
+func Trim() TransformFunc {
+	return infallible(strings.TrimSpace)
+}
+
+// CollapseSpace trims the value and replaces every run of whitespace with a single space.
+func CollapseSpace() TransformFunc {
+	return infallible(func(value string) string {
+		return strings.Join(strings.Fields(value), " ")
+	})
+}
+
+// Upper converts the value to upper case.
+func Upper() TransformFunc {
+	return infallible(strings.ToUpper)
+}
+
+// Lower converts the value to lower case.
+func Lower() TransformFunc {
+	return infallible(strings.ToLower)
+}
+
+// Title converts the value to title case.
+func Title() TransformFunc {
+	return infallible(func(value string) string {
+		return cases.Title(language.Und).String(value)
+	})
+}
+
+// NormalizeNFC converts the value to Unicode normalization form C.
+func NormalizeNFC() TransformFunc {
+	return infallible(norm.NFC.String)
+}
+
+// StripNonPrintable removes every character that is not printable. Spaces are kept.
+func StripNonPrintable() TransformFunc {
+	return infallible(func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if r == utf8.RuneError || !unicode.IsPrint(r) {
+				return -1
+			}
+			return r
+		}, value)
+	})
+}
+
+// ReplaceRegex replaces every match of pattern with repl, which may refer to submatches as in regexp.Regexp.ReplaceAllString.
+func ReplaceRegex(pattern *regexp.Regexp, repl string) TransformFunc {
+	return infallible(func(value string) string {
+		return pattern.ReplaceAllString(value, repl)
+	})
+}
+
+// PadLeft pads the value on the left with pad up to width characters.
+func PadLeft(width int, pad rune) TransformFunc {
+	return infallible(func(value string) string {
+		if n := width - utf8.RuneCountInString(value); n > 0 {
+			return strings.Repeat(string(pad), n) + value
+		}
+		return value
+	})
+}
+
+// PadRight pads the value on the right with pad up to width characters.
+func PadRight(width int, pad rune) TransformFunc {
+	return infallible(func(value string) string {
+		if n := width - utf8.RuneCountInString(value); n > 0 {
+			return value + strings.Repeat(string(pad), n)
+		}
+		return value
+	})
+}
+
+// Truncate cuts the value to at most width characters.
+func Truncate(width int) TransformFunc {
+	return infallible(func(value string) string {
+		runes := []rune(value)
+		if len(runes) > width {
+			return string(runes[:max(width, 0)])
+		}
+		return value
+	})
+}
+
+func infallible(fn func(string) string) TransformFunc {
+	return func(value string) (string, error) {
+		return fn(value), nil
+	}
+}
@@ -0,0 +1,88 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestApply tests Apply.
+func TestApply(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "  alice   SMITH ", "+1 (555) 010-9999"},
+		{"2", "bob\tjones", "555.010.1234"},
+	}, "ID", "Name", "Phone")
+	if err := matrix.Apply("Name", Chain(CollapseSpace(), Lower(), Title())); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Name", "Alice Smith", "Bob Jones")
+
+	digits := ReplaceRegex(regexp.MustCompile(`\D`), "")
+	if err := matrix.Apply("Phone", Chain(digits, PadLeft(11, '1'), Truncate(11))); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Phone", "15550109999", "15550101234")
+
+	// Test failing values are reported and left unchanged.
+	err := matrix.Apply("ID", func(value string) (string, error) {
+		if value == "2" {
+			return "", errors.New("boom")
+		}
+		return "#" + value, nil
+	})
+	var cellErr *CellError
+	if !errors.As(err, &cellErr) || cellErr.Row != 1 || cellErr.Column != "ID" {
+		t.Fatalf("expected cell error at row 1, column ID, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "row 1, column ID: boom") {
+		t.Fatalf("unexpected error message %v", err)
+	}
+	assertColumn(t, matrix, "ID", "#1", "2")
+
+	if err = matrix.Apply("Age", Trim()); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
+
+// TestApplyAll tests ApplyAll and its interaction with schemas and primary keys.
+func TestApplyAll(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{" 1", "x\u0000y "},
+		{"1 ", "Cafe\u0301"},
+	}, "ID", "Name")
+	if err := matrix.ApplyAll(Chain(StripNonPrintable(), NormalizeNFC(), Upper())); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, matrix, "Name", "XY ", "CAFÉ")
+
+	matrix.SetPrimaryKey("ID")
+	err := matrix.ApplyAll(Trim())
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	assertColumn(t, matrix, "ID", "1", "1 ")
+	assertColumn(t, matrix, "Name", "XY", "CAFÉ")
+}
+
+func TestTransforms(t *testing.T) {
+	tests := []struct {
+		fn       TransformFunc
+		in, want string
+	}{
+		{Trim(), " a ", "a"},
+		{CollapseSpace(), " a \n b ", "a b"},
+		{Upper(), "ab", "AB"},
+		{Lower(), "AB", "ab"},
+		{Title(), "hello world", "Hello World"},
+		{PadRight(4, '.'), "ab", "ab.."},
+		{PadLeft(1, '0'), "ab", "ab"},
+		{Truncate(1), "éa", "é"},
+		{Truncate(5), "ab", "ab"},
+	}
+	for _, tt := range tests {
+		if got, _ := tt.fn(tt.in); got != tt.want {
+			t.Fatalf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TransposeNameColumn is the name of the column holding the former header names in a matrix
+// transposed without a header column.
+const TransposeNameColumn = "Column"
+
+// JSONOptions configures the JSON export of ToJSONWith.
+type JSONOptions struct {
+	// Compact indicates whether the output is minified instead of pretty-printed.
+	Compact bool
+	// Columns indicates whether the output is column-oriented, as a single object mapping every
+	// column name to the array of its values in header order, instead of an array of row objects.
+	Columns bool
+}
+
+func (t *bDataMatrix) Transpose(headerFrom string) (BDataMatrix, error) {
+	names := make([]string, t.LenRows())
+	from := -1
+	if headerFrom == "" {
+		for i := range names {
+			names[i] = generatedRowName(i)
+		}
+		headerFrom = TransposeNameColumn
+	} else {
+		idx, exists := t.headerIndex[headerFrom]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, headerFrom)
+		}
+		from = idx
+		headerFrom = t.header[idx]
+		for i, row := range t.rows {
+			names[i] = row[idx]
+		}
+	}
+
+	header := uniqueHeader(append([]string{headerFrom}, names...), func(i int) string {
+		return generatedRowName(i - 1)
+	})
+	result := &bDataMatrix{header: header}
+	for j, key := range t.header {
+		if j == from {
+			continue
+		}
+		row := make([]string, 0, len(header))
+		row = append(row, key)
+		var flags []bool
+		for i := range t.rows {
+			row = append(row, t.rows[i][j])
+			if t.isNullAt(i, j) {
+				if flags == nil {
+					flags = make([]bool, len(header))
+				}
+				flags[i+1] = true
+			}
+		}
+		if flags != nil && result.nulls == nil {
+			result.nulls = make([][]bool, len(result.rows), t.LenColumns())
+		}
+		if result.nulls != nil {
+			result.nulls = append(result.nulls, flags)
+		}
+		result.rows = append(result.rows, row)
+	}
+	if err := result.calculateHeaderIndex(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (t *bDataMatrix) Untranspose() (BDataMatrix, error) {
+	result, err := t.Transpose(t.header[0])
+	if err != nil {
+		return nil, err
+	}
+	// The generated row names of Transpose("") carry no data, so they are dropped instead of
+	// being kept as a column.
+	for i, key := range t.header[1:] {
+		if key != generatedRowName(i) {
+			return result, nil
+		}
+	}
+	if result.LenColumns() == 1 {
+		return result, nil
+	}
+	if err = result.DeleteColumn(t.header[0]); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (t *bDataMatrix) ToJSONWith(opts JSONOptions) StreamingOutput {
+	if opts.Columns {
+		return &outputData{stream: t.snapshot().jsonColumnsStream(opts.Compact)}
+	}
+	return &outputData{stream: t.snapshot().jsonStream(opts.Compact)}
+}
+
+// uniqueHeader resolves empty and duplicate names so they can be used as a header. Empty names are
+// replaced by the name returned by generate for their position, and repeated names get a "_2", "_3",
+// ... suffix.
+func uniqueHeader(names []string, generate func(i int) string) []string {
+	header := make([]string, len(names))
+	used := make(map[string]struct{}, len(names))
+	for i, name := range names {
+		if name == "" {
+			name = generate(i)
+		}
+		candidate := name
+		for n := 2; ; n++ {
+			if _, ok := used[candidate]; !ok {
+				break
+			}
+			candidate = name + "_" + strconv.Itoa(n)
+		}
+		used[candidate] = struct{}{}
+		header[i] = candidate
+	}
+	return header
+}
+
+// generatedRowName returns the header name given to the row at index i by Transpose.
+func generatedRowName(i int) string {
+	return "Row" + strconv.Itoa(i)
+}
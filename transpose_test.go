@@ -0,0 +1,90 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestTranspose tests Transpose by a column and its reverse.
+func TestTranspose(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"Revenue", "100", "120"},
+		{"Cost", "80", "90"},
+	}, "Metric", "Jan", "Feb")
+	matrix.SetNull(1, "Feb")
+
+	transposed, err := matrix.Transpose("Metric")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, transposed, "Metric", "Revenue", "Cost")
+	assertColumn(t, transposed, "Metric", "Jan", "Feb")
+	assertColumn(t, transposed, "Revenue", "100", "120")
+	assertColumn(t, transposed, "Cost", "80", "")
+	if null, _ := transposed.IsNull(1, "Cost"); !null {
+		t.Fatalf("expected null cell to be transposed")
+	}
+
+	restored, err := transposed.Untranspose()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, restored, "Metric", "Jan", "Feb")
+	assertColumn(t, restored, "Metric", "Revenue", "Cost")
+	assertColumn(t, restored, "Feb", "120", "")
+	if null, _ := restored.IsNull(1, "Feb"); !null {
+		t.Fatalf("expected null cell to be restored")
+	}
+
+	if _, err = matrix.Transpose("Missing"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
+
+// TestTransposeGeneratedHeader tests Transpose with generated and duplicate header names.
+func TestTransposeGeneratedHeader(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"a", "1"},
+		{"a", "2"},
+		{"", "3"},
+	}, "Key", "Value")
+
+	transposed, err := matrix.Transpose("Key")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, transposed, "Key", "a", "a_2", "Row2")
+
+	generated, err := matrix.Transpose("")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, generated, TransposeNameColumn, "Row0", "Row1", "Row2")
+	assertColumn(t, generated, TransposeNameColumn, "Key", "Value")
+	assertColumn(t, generated, "Row2", "", "3")
+
+	restored, err := generated.Untranspose()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, restored, "Key", "Value")
+	assertColumn(t, restored, "Value", "1", "2", "3")
+}
+
+// TestToJSONColumns tests the column-oriented JSON export.
+func TestToJSONColumns(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice"},
+		{"2", "Bob"},
+	}, "ID", "Name")
+	matrix.SetNull(1, "Name")
+
+	got := matrix.ToJSONWith(JSONOptions{Compact: true, Columns: true}).String()
+	expected := `{"ID":["1","2"],"Name":["Alice",null]}`
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if got, expected = matrix.ToJSON(true).String(), `[{"ID":"1","Name":"Alice"},{"ID":"2","Name":null}]`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,346 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule defines a validation rule applied by Validate.
+//
+// Built-in rules are created with Required, Unique, OneOf, Matches, MinLength, MaxLength,
+// Range, ForeignKey and RowRule. Apart from Required, column rules ignore empty values.
+type Rule interface {
+	// Name returns the name of the rule reported in violations.
+	Name() string
+
+	check(t *bDataMatrix) ([]Violation, error)
+	// checkRow checks the row at index i against the other rows of t.
+	checkRow(t *bDataMatrix, i int) ([]Violation, error)
+}
+
+// Violation describes a single value or row that broke a rule.
+type Violation struct {
+	// Row is the index of the offending row.
+	Row int
+	// Column is the header name of the offending column. It is empty for row rules.
+	Column string
+	// Value is the offending value. It is empty for row rules.
+	Value string
+	// Rule is the name of the broken rule.
+	Rule string
+	// Message explains why the rule was broken.
+	Message string
+}
+
+// ValidationReport lists every violation found by Validate, ordered by row index.
+type ValidationReport struct {
+	// Violations holds every violation found.
+	Violations []Violation
+}
+
+// Valid reports whether no violation has been found.
+func (r ValidationReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Err returns an error wrapping ErrValidationFailed when the report holds violations, or nil otherwise.
+func (r ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	v := r.Violations[0]
+	return fmt.Errorf("%w: %d violation(s), first at row %d: %s", ErrValidationFailed, len(r.Violations), v.Row, v.Message)
+}
+
+// Matrix exports the report as a BDataMatrix with the columns "Row", "Column", "Value", "Rule" and "Message".
+func (r ValidationReport) Matrix() BDataMatrix {
+	rows := make([][]string, len(r.Violations))
+	for i, v := range r.Violations {
+		rows[i] = []string{strconv.Itoa(v.Row), v.Column, v.Value, v.Rule, v.Message}
+	}
+	m, _ := NewWithData(rows, "Row", "Column", "Value", "Rule", "Message")
+	return m
+}
+
+func (t *bDataMatrix) Validate(rules ...Rule) (ValidationReport, error) {
+	var report ValidationReport
+	for _, rule := range rules {
+		violations, err := rule.check(t)
+		if err != nil {
+			return ValidationReport{}, fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+		report.Violations = append(report.Violations, violations...)
+	}
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		return report.Violations[i].Row < report.Violations[j].Row
+	})
+	return report, nil
+}
+
+// validateRows checks the rows at indexes against rules, as Validate does for every row. Unique
+// reports a row holding a value of any other row, not only of an earlier one.
+func (t *bDataMatrix) validateRows(indexes []int, rules ...Rule) (ValidationReport, error) {
+	var report ValidationReport
+	for _, i := range indexes {
+		for _, rule := range rules {
+			violations, err := rule.checkRow(t, i)
+			if err != nil {
+				return ValidationReport{}, fmt.Errorf("rule %s: %w", rule.Name(), err)
+			}
+			report.Violations = append(report.Violations, violations...)
+		}
+	}
+	return report, nil
+}
+
+// Required creates a rule that rejects empty or whitespace-only values in a column.
+func Required(key string) Rule {
+	return &columnRule{name: "required", key: key, keepEmpty: true, validate: func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("value is required")
+		}
+		return nil
+	}}
+}
+
+// Unique creates a rule that rejects values appearing more than once in a column.
+// The first occurrence of a value is accepted and every later occurrence is reported.
+func Unique(key string) Rule {
+	return &uniqueRule{key: key}
+}
+
+// OneOf creates a rule that rejects values of a column that are not in the allowed values.
+func OneOf(key string, values ...string) Rule {
+	allowed := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		allowed[v] = struct{}{}
+	}
+	return &columnRule{name: "one_of", key: key, validate: func(value string) error {
+		if _, ok := allowed[value]; !ok {
+			return fmt.Errorf("value must be one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}}
+}
+
+// Matches creates a rule that rejects values of a column that do not match the pattern.
+func Matches(key string, pattern *regexp.Regexp) Rule {
+	return &columnRule{name: "regex", key: key, validate: func(value string) error {
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("value does not match %s", pattern)
+		}
+		return nil
+	}}
+}
+
+// MinLength creates a rule that rejects values of a column shorter than n characters.
+func MinLength(key string, n int) Rule {
+	return &columnRule{name: "min_length", key: key, validate: func(value string) error {
+		if utf8.RuneCountInString(value) < n {
+			return fmt.Errorf("value is shorter than %d characters", n)
+		}
+		return nil
+	}}
+}
+
+// MaxLength creates a rule that rejects values of a column longer than n characters.
+func MaxLength(key string, n int) Rule {
+	return &columnRule{name: "max_length", key: key, validate: func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("value is longer than %d characters", n)
+		}
+		return nil
+	}}
+}
+
+// Range creates a rule that rejects values of a column that are not numbers between min and max inclusive.
+func Range(key string, min, max float64) Rule {
+	return &columnRule{name: "range", key: key, validate: func(value string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return errors.New("value is not a number")
+		}
+		if f < min || f > max {
+			return fmt.Errorf("value is not between %v and %v", min, max)
+		}
+		return nil
+	}}
+}
+
+// ForeignKey creates a rule that rejects values of a column that do not exist in the column refKey of ref.
+func ForeignKey(key string, ref BDataMatrix, refKey string) Rule {
+	return &foreignKeyRule{key: key, ref: ref, refKey: refKey}
+}
+
+// RowRule creates a custom rule that validates whole rows. A row is reported when fn returns an error,
+// using the error text as the violation message.
+func RowRule(name string, fn func(row Row) error) Rule {
+	return &rowRule{name: name, fn: fn}
+}
+
+type columnRule struct {
+	name      string
+	key       string
+	keepEmpty bool
+	validate  func(value string) error
+}
+
+func (r *columnRule) Name() string {
+	return r.name
+}
+
+func (r *columnRule) check(t *bDataMatrix) ([]Violation, error) {
+	if _, exists := t.headerIndex[r.key]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, r.key)
+	}
+	var violations []Violation
+	for i := range t.rows {
+		v, err := r.checkRow(t, i)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+	return violations, nil
+}
+
+func (r *columnRule) checkRow(t *bDataMatrix, i int) ([]Violation, error) {
+	idx, exists := t.headerIndex[r.key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, r.key)
+	}
+	value := t.rows[i][idx]
+	if value == "" && !r.keepEmpty {
+		return nil, nil
+	}
+	if err := r.validate(value); err != nil {
+		return []Violation{{Row: i, Column: r.key, Value: value, Rule: r.name, Message: err.Error()}}, nil
+	}
+	return nil, nil
+}
+
+type uniqueRule struct {
+	key string
+}
+
+func (r *uniqueRule) Name() string {
+	return "unique"
+}
+
+func (r *uniqueRule) check(t *bDataMatrix) ([]Violation, error) {
+	idx, exists := t.headerIndex[r.key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, r.key)
+	}
+	seen := make(map[string]int)
+	var violations []Violation
+	for i, row := range t.rows {
+		value := row[idx]
+		if value == "" {
+			continue
+		}
+		if first, ok := seen[value]; ok {
+			violations = append(violations, Violation{
+				Row: i, Column: r.key, Value: value, Rule: r.Name(),
+				Message: fmt.Sprintf("value duplicates row %d", first),
+			})
+			continue
+		}
+		seen[value] = i
+	}
+	return violations, nil
+}
+
+func (r *uniqueRule) checkRow(t *bDataMatrix, i int) ([]Violation, error) {
+	idx, exists := t.headerIndex[r.key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, r.key)
+	}
+	value := t.rows[i][idx]
+	if value == "" {
+		return nil, nil
+	}
+	for j, row := range t.rows {
+		if j != i && row[idx] == value {
+			return []Violation{{
+				Row: i, Column: r.key, Value: value, Rule: r.Name(),
+				Message: fmt.Sprintf("value duplicates row %d", j),
+			}}, nil
+		}
+	}
+	return nil, nil
+}
+
+type foreignKeyRule struct {
+	key    string
+	ref    BDataMatrix
+	refKey string
+}
+
+func (r *foreignKeyRule) Name() string {
+	return "foreign_key"
+}
+
+func (r *foreignKeyRule) check(t *bDataMatrix) ([]Violation, error) {
+	rule, err := r.columnRule()
+	if err != nil {
+		return nil, err
+	}
+	return rule.check(t)
+}
+
+func (r *foreignKeyRule) checkRow(t *bDataMatrix, i int) ([]Violation, error) {
+	rule, err := r.columnRule()
+	if err != nil {
+		return nil, err
+	}
+	return rule.checkRow(t, i)
+}
+
+// columnRule returns a rule rejecting values missing from the referenced column.
+func (r *foreignKeyRule) columnRule() (*columnRule, error) {
+	refValues, err := r.ref.GetColumn(r.refKey)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]struct{}, len(refValues))
+	for _, v := range refValues {
+		known[v] = struct{}{}
+	}
+	return &columnRule{name: r.Name(), key: r.key, validate: func(value string) error {
+		if _, ok := known[value]; !ok {
+			return fmt.Errorf("value does not exist in referenced column '%s'", r.refKey)
+		}
+		return nil
+	}}, nil
+}
+
+type rowRule struct {
+	name string
+	fn   func(row Row) error
+}
+
+func (r *rowRule) Name() string {
+	return r.name
+}
+
+func (r *rowRule) check(t *bDataMatrix) ([]Violation, error) {
+	var violations []Violation
+	for i := range t.rows {
+		v, _ := r.checkRow(t, i)
+		violations = append(violations, v...)
+	}
+	return violations, nil
+}
+
+func (r *rowRule) checkRow(t *bDataMatrix, i int) ([]Violation, error) {
+	if err := r.fn(t.row(i)); err != nil {
+		return []Violation{{Row: i, Rule: r.name, Message: err.Error()}}, nil
+	}
+	return nil, nil
+}
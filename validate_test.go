@@ -0,0 +1,118 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+// TestValidate tests Validate with the built-in rules.
+func TestValidate(t *testing.T) {
+	countries, _ := NewWithData([][]string{{"ID"}, {"FR"}}, "Code")
+	matrix, _ := NewWithData([][]string{
+		{"1", "Alice", "alice@example.com", "30", "ID", "active"},
+		{"2", "", "bob", "250", "XX", "gone"},
+		{"1", "Bo", "carol@example.com", "abc", "FR", "active"},
+	}, "ID", "Name", "Email", "Age", "Country", "Status")
+
+	report, err := matrix.Validate(
+		Required("Name"),
+		Unique("ID"),
+		OneOf("Status", "active", "inactive"),
+		Matches("Email", regexp.MustCompile(`^[^@]+@[^@]+$`)),
+		MinLength("Name", 3),
+		MaxLength("Email", 16),
+		Range("Age", 0, 150),
+		ForeignKey("Country", countries, "Code"),
+		RowRule("adult_alice", func(row Row) error {
+			name, _ := row.Get("Name")
+			age, _ := row.Get("Age")
+			if name == "Alice" && age != "30" {
+				return fmt.Errorf("alice must be 30")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]int{
+		"required":    1,
+		"unique":      1,
+		"one_of":      1,
+		"regex":       1,
+		"min_length":  1,
+		"max_length":  2,
+		"range":       2,
+		"foreign_key": 1,
+	}
+	got := make(map[string]int)
+	for _, v := range report.Violations {
+		got[v.Rule]++
+	}
+	for rule, n := range expected {
+		if got[rule] != n {
+			t.Fatalf("expected %d violation(s) for rule %s, got %d", n, rule, got[rule])
+		}
+	}
+	if len(report.Violations) != 10 {
+		t.Fatalf("expected 10 violations, got %d", len(report.Violations))
+	}
+	for i := 1; i < len(report.Violations); i++ {
+		if report.Violations[i-1].Row > report.Violations[i].Row {
+			t.Fatal("expected violations ordered by row")
+		}
+	}
+	if report.Valid() || !errors.Is(report.Err(), ErrValidationFailed) {
+		t.Fatal("expected invalid report")
+	}
+
+	// Test report export.
+	exported := report.Matrix()
+	if exported.LenRows() != 10 || exported.LenColumns() != 5 {
+		t.Fatalf("expected 10x5 report matrix, got %dx%d", exported.LenRows(), exported.LenColumns())
+	}
+
+	// Test row rule violation.
+	report, _ = matrix.Validate(RowRule("no_bo", func(row Row) error {
+		if name, _ := row.Get("Name"); name == "Bo" {
+			return errors.New("bo is not allowed")
+		}
+		return nil
+	}))
+	if len(report.Violations) != 1 || report.Violations[0].Row != 2 || report.Violations[0].Rule != "no_bo" {
+		t.Fatalf("unexpected row rule violations: %+v", report.Violations)
+	}
+
+	// Test valid data.
+	report, err = matrix.Validate(Required("ID"))
+	if err != nil || !report.Valid() || report.Err() != nil {
+		t.Fatalf("expected valid report, got %+v, %v", report, err)
+	}
+
+	// Test unknown column.
+	_, err = matrix.Validate(Required("Gender"))
+	if !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	_, err = matrix.Validate(ForeignKey("Country", countries, "Name"))
+	if !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
+
+func TestRow(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Alice"}}, "ID", "Name")
+	var seen Row
+	matrix.Validate(RowRule("capture", func(row Row) error {
+		seen = row
+		return nil
+	}))
+	if seen.Index() != 0 || len(seen.Values()) != 2 {
+		t.Fatalf("unexpected row %+v", seen)
+	}
+	if _, err := seen.Get("Age"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+}
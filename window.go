@@ -0,0 +1,394 @@
+package bdatamatrix
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortKey specifies a column to order rows by.
+type SortKey struct {
+	// Column is the header name of the column to order by.
+	Column string
+	// Desc indicates whether the order is descending.
+	Desc bool
+	// Numeric indicates whether values are compared as numbers. Numbers sort before values that
+	// are not numbers, such as empty values, in both directions. Values that are not numbers are
+	// compared as strings.
+	Numeric bool
+}
+
+// compare returns -1, 0 or 1 depending on whether a sorts before, with or after b.
+func (k SortKey) compare(a, b string) int {
+	if k.Numeric {
+		// Values that are not numbers sort last in both directions.
+		_, okA := sortNumber(a)
+		if _, okB := sortNumber(b); okA != okB {
+			if okA {
+				return -1
+			}
+			return 1
+		}
+	}
+	c := compareValues(a, b, k.Numeric)
+	if k.Desc {
+		return -c
+	}
+	return c
+}
+
+// Window groups rows into partitions ordered by sort keys, to compute window functions.
+// It is created by BDataMatrix.Window.
+//
+// Example usage:
+//
+//	w := matrix.Window([]string{"Region"}, []SortKey{{Column: "Sales", Desc: true, Numeric: true}})
+//	if err := w.Add(Rank(), "SalesRank"); err != nil {
+//	    // handle error
+//	}
+//	if err := w.Add(CumSum("Sales"), "RunningSales"); err != nil {
+//	    // handle error
+//	}
+type Window struct {
+	m           *bDataMatrix
+	partitionBy []string
+	orderBy     []SortKey
+}
+
+func (t *bDataMatrix) Window(partitionBy []string, orderBy []SortKey) *Window {
+	return &Window{m: t, partitionBy: partitionBy, orderBy: orderBy}
+}
+
+// Add computes fn over every partition and appends the results as a new column.
+//
+// Parameters:
+//   - fn: The window function to compute, such as RowNumber, Rank or CumSum.
+//   - outputColumn: The name of the column to add.
+//
+// Returns:
+//   - An error if a column does not exist, a value cannot be computed or the column cannot be added.
+func (w *Window) Add(fn WindowFunc, outputColumn string) error {
+	partitions, err := w.partitions()
+	if err != nil {
+		return err
+	}
+	result := make([]string, w.m.LenRows())
+	for _, p := range partitions {
+		values, err := fn.compute(p)
+		if err != nil {
+			return fmt.Errorf("window function %s: %w", fn.Name(), err)
+		}
+		for i, index := range p.indexes {
+			result[index] = values[i]
+		}
+	}
+	return w.m.AddColumn(outputColumn, result...)
+}
+
+// partitions groups the row indexes by partition, in order of first appearance, and orders each partition.
+func (w *Window) partitions() ([]*windowPartition, error) {
+	t := w.m
+	for _, key := range w.partitionBy {
+		if _, exists := t.headerIndex[key]; !exists {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+		}
+	}
+	for _, key := range w.orderBy {
+		if _, exists := t.headerIndex[key.Column]; !exists {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key.Column)
+		}
+	}
+	groups := make(map[string]*windowPartition)
+	var partitions []*windowPartition
+	for i, row := range t.rows {
+		k := encodeKey(rowKeyValues(row, t.headerIndex, w.partitionBy))
+		p, exists := groups[k]
+		if !exists {
+			p = &windowPartition{m: t, orderBy: w.orderBy}
+			groups[k] = p
+			partitions = append(partitions, p)
+		}
+		p.indexes = append(p.indexes, i)
+	}
+	for _, p := range partitions {
+		sort.SliceStable(p.indexes, func(i, j int) bool {
+			return p.compare(i, j) < 0
+		})
+	}
+	return partitions, nil
+}
+
+// windowPartition holds the ordered row indexes of a single partition.
+type windowPartition struct {
+	m       *bDataMatrix
+	orderBy []SortKey
+	indexes []int
+}
+
+// compare compares the rows at positions i and j of the partition using the sort keys.
+func (p *windowPartition) compare(i, j int) int {
+	a, b := p.m.rows[p.indexes[i]], p.m.rows[p.indexes[j]]
+	for _, key := range p.orderBy {
+		idx := p.m.headerIndex[key.Column]
+		if c := key.compare(a[idx], b[idx]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// column returns the values of a column in partition order.
+func (p *windowPartition) column(key string) ([]string, error) {
+	idx, exists := p.m.headerIndex[key]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, key)
+	}
+	values := make([]string, len(p.indexes))
+	for i, index := range p.indexes {
+		values[i] = p.m.rows[index][idx]
+	}
+	return values, nil
+}
+
+// WindowFunc defines a function computed over the ordered rows of each partition of a Window.
+//
+// Window functions are created with RowNumber, Rank, DenseRank, Lag, Lead, CumSum, CumCount,
+// CumMin, CumMax and MovingAvg.
+type WindowFunc interface {
+	// Name returns the name of the window function.
+	Name() string
+
+	compute(p *windowPartition) ([]string, error)
+}
+
+type windowFunc struct {
+	name string
+	fn   func(p *windowPartition) ([]string, error)
+}
+
+func (f *windowFunc) Name() string {
+	return f.name
+}
+
+func (f *windowFunc) compute(p *windowPartition) ([]string, error) {
+	return f.fn(p)
+}
+
+// RowNumber numbers the rows of each partition from 1.
+func RowNumber() WindowFunc {
+	return &windowFunc{name: "row_number", fn: func(p *windowPartition) ([]string, error) {
+		values := make([]string, len(p.indexes))
+		for i := range values {
+			values[i] = strconv.Itoa(i + 1)
+		}
+		return values, nil
+	}}
+}
+
+// Rank ranks the rows of each partition from 1, giving ties the same rank and leaving gaps after them.
+func Rank() WindowFunc {
+	return &windowFunc{name: "rank", fn: func(p *windowPartition) ([]string, error) {
+		values := make([]string, len(p.indexes))
+		rank := 0
+		for i := range values {
+			if i == 0 || p.compare(i-1, i) != 0 {
+				rank = i + 1
+			}
+			values[i] = strconv.Itoa(rank)
+		}
+		return values, nil
+	}}
+}
+
+// DenseRank ranks the rows of each partition from 1, giving ties the same rank without gaps.
+func DenseRank() WindowFunc {
+	return &windowFunc{name: "dense_rank", fn: func(p *windowPartition) ([]string, error) {
+		values := make([]string, len(p.indexes))
+		rank := 0
+		for i := range values {
+			if i == 0 || p.compare(i-1, i) != 0 {
+				rank++
+			}
+			values[i] = strconv.Itoa(rank)
+		}
+		return values, nil
+	}}
+}
+
+// Lag returns the value of a column offset rows before the current row of the partition, or def when there is none.
+func Lag(key string, offset int, def string) WindowFunc {
+	return &windowFunc{name: "lag", fn: shift(key, -offset, def)}
+}
+
+// Lead returns the value of a column offset rows after the current row of the partition, or def when there is none.
+func Lead(key string, offset int, def string) WindowFunc {
+	return &windowFunc{name: "lead", fn: shift(key, offset, def)}
+}
+
+func shift(key string, offset int, def string) func(p *windowPartition) ([]string, error) {
+	return func(p *windowPartition) ([]string, error) {
+		column, err := p.column(key)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(column))
+		for i := range values {
+			if j := i + offset; j >= 0 && j < len(column) {
+				values[i] = column[j]
+			} else {
+				values[i] = def
+			}
+		}
+		return values, nil
+	}
+}
+
+// CumSum returns the running sum of a numeric column over the partition. Empty values are skipped.
+func CumSum(key string) WindowFunc {
+	return &windowFunc{name: "cum_sum", fn: cumulative(key, func(acc, v float64) float64 { return acc + v })}
+}
+
+// CumMin returns the running minimum of a numeric column over the partition. Empty values are skipped.
+func CumMin(key string) WindowFunc {
+	return &windowFunc{name: "cum_min", fn: cumulative(key, func(acc, v float64) float64 { return min(acc, v) })}
+}
+
+// CumMax returns the running maximum of a numeric column over the partition. Empty values are skipped.
+func CumMax(key string) WindowFunc {
+	return &windowFunc{name: "cum_max", fn: cumulative(key, func(acc, v float64) float64 { return max(acc, v) })}
+}
+
+func cumulative(key string, combine func(acc, v float64) float64) func(p *windowPartition) ([]string, error) {
+	return func(p *windowPartition) ([]string, error) {
+		numbers, err := p.numbers(key)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(numbers))
+		var acc float64
+		seen := false
+		for i, n := range numbers {
+			if n != nil {
+				if seen {
+					acc = combine(acc, *n)
+				} else {
+					acc, seen = *n, true
+				}
+			}
+			if seen {
+				values[i] = formatFloat(acc)
+			}
+		}
+		return values, nil
+	}
+}
+
+// CumCount returns the running count of non-empty values of a column over the partition.
+func CumCount(key string) WindowFunc {
+	return &windowFunc{name: "cum_count", fn: func(p *windowPartition) ([]string, error) {
+		column, err := p.column(key)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(column))
+		count := 0
+		for i, v := range column {
+			if v != "" {
+				count++
+			}
+			values[i] = strconv.Itoa(count)
+		}
+		return values, nil
+	}}
+}
+
+// MovingAvg returns the average of a numeric column over the current row and up to n-1 previous rows
+// of the partition. Empty values are skipped.
+func MovingAvg(key string, n int) WindowFunc {
+	return &windowFunc{name: "moving_avg", fn: func(p *windowPartition) ([]string, error) {
+		if n < 1 {
+			return nil, fmt.Errorf("window size must be positive, got %d", n)
+		}
+		numbers, err := p.numbers(key)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(numbers))
+		for i := range numbers {
+			var sum float64
+			count := 0
+			for j := max(0, i-n+1); j <= i; j++ {
+				if numbers[j] != nil {
+					sum += *numbers[j]
+					count++
+				}
+			}
+			if count > 0 {
+				values[i] = formatFloat(sum / float64(count))
+			}
+		}
+		return values, nil
+	}}
+}
+
+// numbers returns the values of a column in partition order parsed as numbers, with nil for empty values.
+func (p *windowPartition) numbers(key string) ([]*float64, error) {
+	column, err := p.column(key)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]*float64, len(column))
+	for i, v := range column {
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: column '%s' value '%s' is not a number", p.indexes[i], key, v)
+		}
+		numbers[i] = &f
+	}
+	return numbers, nil
+}
+
+// compareValues returns -1, 0 or 1 depending on whether a sorts before, with or after b. When
+// numeric is true, numbers are compared as numbers and sort before every value that is not a
+// number, such as an empty value, so that the order is total.
+func compareValues(a, b string, numeric bool) int {
+	if numeric {
+		fa, okA := sortNumber(a)
+		fb, okB := sortNumber(b)
+		switch {
+		case okA && okB:
+			return compareFloats(fa, fb)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// sortNumber parses a value sorted as a number. NaN is sorted as a string, as it has no order.
+func sortNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil && !math.IsNaN(f)
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,119 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestWindowRanking tests RowNumber, Rank and DenseRank.
+func TestWindowRanking(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"EU", "Alice", "100"},
+		{"US", "Bob", "50"},
+		{"EU", "Carol", "300"},
+		{"EU", "Dave", "100"},
+		{"US", "Erin", ""},
+		{"US", "Frank", "9"},
+	}, "Region", "Name", "Sales")
+	w := matrix.Window([]string{"Region"}, []SortKey{{Column: "Sales", Desc: true, Numeric: true}})
+	if err := w.Add(RowNumber(), "RowNumber"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	w.Add(Rank(), "Rank")
+	w.Add(DenseRank(), "DenseRank")
+	assertColumn(t, matrix, "RowNumber", "2", "1", "1", "3", "3", "2")
+	assertColumn(t, matrix, "Rank", "2", "1", "1", "2", "3", "2")
+	assertColumn(t, matrix, "DenseRank", "2", "1", "1", "2", "3", "2")
+
+	// Test a single partition without ordering keeps the row order.
+	matrix.Window(nil, nil).Add(RowNumber(), "Position")
+	assertColumn(t, matrix, "Position", "1", "2", "3", "4", "5", "6")
+
+	// Test ranks with gaps.
+	matrix.Window(nil, []SortKey{{Column: "Region"}}).Add(Rank(), "RegionRank")
+	matrix.Window(nil, []SortKey{{Column: "Region"}}).Add(DenseRank(), "RegionDenseRank")
+	assertColumn(t, matrix, "RegionRank", "1", "4", "1", "1", "4", "4")
+	assertColumn(t, matrix, "RegionDenseRank", "1", "2", "1", "1", "2", "2")
+}
+
+// TestWindowNumericOrder tests that numbers sort before other values in both directions.
+func TestWindowNumericOrder(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"b"}, {"10"}, {""}, {"2"}, {"a"}, {"NaN"}}, "Value")
+	matrix.Window(nil, []SortKey{{Column: "Value", Numeric: true}}).Add(RowNumber(), "Asc")
+	matrix.Window(nil, []SortKey{{Column: "Value", Desc: true, Numeric: true}}).Add(RowNumber(), "Desc")
+	assertColumn(t, matrix, "Asc", "6", "2", "3", "1", "5", "4")
+	assertColumn(t, matrix, "Desc", "3", "1", "6", "2", "4", "5")
+}
+
+// TestWindowOffsets tests Lag and Lead.
+func TestWindowOffsets(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"EU", "Alice", "100"},
+		{"US", "Bob", "50"},
+		{"EU", "Carol", "300"},
+		{"EU", "Dave", "100"},
+		{"US", "Erin", ""},
+		{"US", "Frank", "9"},
+	}, "Region", "Name", "Sales")
+	w := matrix.Window([]string{"Region"}, nil)
+	w.Add(Lag("Name", 1, "-"), "Previous")
+	w.Add(Lead("Name", 2, "-"), "AfterNext")
+	assertColumn(t, matrix, "Previous", "-", "-", "Alice", "Carol", "Bob", "Erin")
+	assertColumn(t, matrix, "AfterNext", "Dave", "Frank", "-", "-", "-", "-")
+}
+
+// TestWindowAggregates tests the cumulative functions and MovingAvg.
+func TestWindowAggregates(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"EU", "Alice", "100"},
+		{"US", "Bob", "50"},
+		{"EU", "Carol", "300"},
+		{"EU", "Dave", "100"},
+		{"US", "Erin", ""},
+		{"US", "Frank", "9"},
+	}, "Region", "Name", "Sales")
+	w := matrix.Window([]string{"Region"}, nil)
+	w.Add(CumSum("Sales"), "CumSum")
+	w.Add(CumCount("Sales"), "CumCount")
+	w.Add(CumMin("Sales"), "CumMin")
+	w.Add(CumMax("Sales"), "CumMax")
+	w.Add(MovingAvg("Sales", 2), "MovingAvg")
+	assertColumn(t, matrix, "CumSum", "100", "50", "400", "500", "50", "59")
+	assertColumn(t, matrix, "CumCount", "1", "1", "2", "3", "1", "2")
+	assertColumn(t, matrix, "CumMin", "100", "50", "100", "100", "50", "9")
+	assertColumn(t, matrix, "CumMax", "100", "50", "300", "300", "50", "50")
+	assertColumn(t, matrix, "MovingAvg", "100", "50", "200", "200", "50", "9")
+}
+
+// TestWindowErrors tests Window errors.
+func TestWindowErrors(t *testing.T) {
+	matrix, _ := NewWithData([][]string{
+		{"EU", "Alice", "100"},
+		{"US", "Bob", "50"},
+		{"EU", "Carol", "300"},
+		{"EU", "Dave", "100"},
+		{"US", "Erin", ""},
+		{"US", "Frank", "9"},
+	}, "Region", "Name", "Sales")
+	if err := matrix.Window([]string{"Country"}, nil).Add(RowNumber(), "N"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.Window(nil, []SortKey{{Column: "Country"}}).Add(RowNumber(), "N"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.Window(nil, nil).Add(CumSum("Name"), "N"); err == nil {
+		t.Fatal("expected error for non-numeric values, got nil")
+	}
+	if err := matrix.Window(nil, nil).Add(Lag("Country", 1, ""), "N"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected column not found error, got %v", err)
+	}
+	if err := matrix.Window(nil, nil).Add(MovingAvg("Sales", 0), "N"); err == nil {
+		t.Fatal("expected error for invalid window size, got nil")
+	}
+	if err := matrix.Window(nil, nil).Add(RowNumber(), "Name"); !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+	if matrix.LenColumns() != 3 {
+		t.Fatalf("expected no column to be added, got %v", matrix.Header())
+	}
+}
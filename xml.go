@@ -0,0 +1,252 @@
+package bdatamatrix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// XMLOptions specifies how ToXML writes XML.
+type XMLOptions struct {
+	// Root is the name of the root element. The zero value means "rows".
+	Root string
+	// Row is the name of the element of each row. The zero value means "row".
+	Row string
+	// Attributes indicates whether cells are written as attributes of the row element instead of
+	// child elements.
+	Attributes bool
+	// Indent indicates whether elements are written on their own indented lines.
+	Indent bool
+	// OmitDeclaration indicates whether the <?xml ...?> declaration is left out.
+	OmitDeclaration bool
+}
+
+func (t *bDataMatrix) ToXML(opts XMLOptions) StreamingOutput {
+	return &outputData{stream: t.snapshot().xmlStream(opts)}
+}
+
+func (t *bDataMatrix) WriteXML(w io.Writer, opts XMLOptions) error {
+	return runStream(t.xmlStream(opts), w)
+}
+
+// xmlStream exports the matrix as XML. Header names are sanitized into valid XML names, and null
+// cells are left out.
+func (t *bDataMatrix) xmlStream(opts XMLOptions) stream {
+	if opts.Root == "" {
+		opts.Root = "rows"
+	}
+	if opts.Row == "" {
+		opts.Row = "row"
+	}
+	return func(w io.Writer) func() error {
+		enc := xml.NewEncoder(w)
+		if opts.Indent {
+			enc.Indent("", "  ")
+		}
+		root := xml.StartElement{Name: xml.Name{Local: xmlName(opts.Root)}}
+		rowName := xml.Name{Local: xmlName(opts.Row)}
+		var names []xml.Name
+		return exportSteps{
+			count: t.LenRows,
+			begin: func() error {
+				sanitized := make([]string, len(t.header))
+				for j, key := range t.header {
+					sanitized[j] = xmlName(key)
+				}
+				for _, name := range uniqueHeader(sanitized, func(int) string { return "_" }) {
+					names = append(names, xml.Name{Local: name})
+				}
+				if !opts.OmitDeclaration {
+					if _, err := io.WriteString(w, xml.Header); err != nil {
+						return err
+					}
+				}
+				return enc.EncodeToken(root)
+			},
+			item: func(i int) error {
+				row := xml.StartElement{Name: rowName}
+				if opts.Attributes {
+					for j, value := range t.rows[i] {
+						if !t.isNullAt(i, j) {
+							row.Attr = append(row.Attr, xml.Attr{Name: names[j], Value: value})
+						}
+					}
+					if err := enc.EncodeToken(row); err != nil {
+						return err
+					}
+					return enc.EncodeToken(row.End())
+				}
+				if err := enc.EncodeToken(row); err != nil {
+					return err
+				}
+				for j, value := range t.rows[i] {
+					if t.isNullAt(i, j) {
+						continue
+					}
+					if err := enc.EncodeElement(value, xml.StartElement{Name: names[j]}); err != nil {
+						return err
+					}
+				}
+				return enc.EncodeToken(row.End())
+			},
+			flush: enc.Flush,
+			end: func() error {
+				if err := enc.EncodeToken(root.End()); err != nil {
+					return err
+				}
+				if err := enc.Flush(); err != nil {
+					return err
+				}
+				if !opts.Indent {
+					return nil
+				}
+				_, err := io.WriteString(w, "\n")
+				return err
+			},
+		}.next()
+	}
+}
+
+// xmlName sanitizes s into a valid XML element or attribute name, without namespace prefix.
+// Invalid characters become underscores, and names that cannot start with their first character
+// are prefixed with an underscore.
+func xmlName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		nameStart := r == '_' || unicode.IsLetter(r)
+		nameChar := nameStart || unicode.IsDigit(r) || r == '-' || r == '.'
+		switch {
+		case i == 0 && !nameStart && nameChar:
+			b.WriteByte('_')
+			b.WriteRune(r)
+		case !nameChar:
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// FromXML imports rows from repeated XML elements. Gzip input is decompressed transparently.
+//
+// rowPath holds the names of the row element and of its parents, separated by slashes, such as
+// "row" or "export/rows/row". An element is a row when the end of its path matches rowPath, or its
+// whole path when rowPath starts with a slash. The attributes and the child elements of each row
+// become columns, in order of first appearance, and the text of a child element becomes its value.
+// Columns missing from a row are null cells. A row that names a column twice, such as with an
+// attribute and a child element of the same name, is malformed input.
+//
+// Example usage:
+//
+//	matrix, err := FromXML(f, "catalog/book")
+//	if err != nil {
+//	    // handle error
+//	}
+func FromXML(r io.Reader, rowPath string) (BDataMatrix, error) {
+	anchored := strings.HasPrefix(rowPath, "/")
+	want := strings.Split(strings.Trim(rowPath, "/"), "/")
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := xml.NewDecoder(dr)
+
+	var header []string
+	seen := make(map[string]struct{})
+	var rows []map[string]string
+	set := func(row map[string]string, key, value string) error {
+		if _, ok := row[key]; ok {
+			return fmt.Errorf("%w: row %d names '%s' more than once", ErrMalformedInput, len(rows), key)
+		}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			header = append(header, key)
+		}
+		row[key] = value
+		return nil
+	}
+
+	var path []string
+	// rowDepth is the depth of the current row element, or 0 outside of rows.
+	rowDepth := 0
+	var row map[string]string
+	var field string
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedInput, err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			path = append(path, tok.Name.Local)
+			switch {
+			case rowDepth == 0 && matchXMLPath(path, want, anchored):
+				rowDepth = len(path)
+				row = make(map[string]string)
+				for _, attr := range tok.Attr {
+					if err := set(row, attr.Name.Local, attr.Value); err != nil {
+						return nil, err
+					}
+				}
+			case rowDepth > 0 && len(path) == rowDepth+1:
+				field = tok.Name.Local
+				text.Reset()
+			}
+		case xml.CharData:
+			if rowDepth > 0 && len(path) > rowDepth {
+				text.Write(tok)
+			}
+		case xml.EndElement:
+			switch {
+			case rowDepth > 0 && len(path) == rowDepth+1:
+				if err := set(row, field, text.String()); err != nil {
+					return nil, err
+				}
+			case rowDepth > 0 && len(path) == rowDepth:
+				rows = append(rows, row)
+				rowDepth = 0
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no elements match '%s'", ErrNoRowsFound, rowPath)
+	}
+	if len(header) == 0 {
+		return nil, ErrEmptyHeader
+	}
+
+	nm, err := New(header...)
+	if err != nil {
+		return nil, err
+	}
+	m := nm.(*bDataMatrix)
+	for _, values := range rows {
+		m.addValues(values)
+	}
+	return m, nil
+}
+
+// matchXMLPath reports whether the element path matches the wanted row path.
+func matchXMLPath(path, want []string, anchored bool) bool {
+	if len(path) < len(want) || (anchored && len(path) != len(want)) {
+		return false
+	}
+	offset := len(path) - len(want)
+	for i, name := range want {
+		if path[offset+i] != name {
+			return false
+		}
+	}
+	return true
+}
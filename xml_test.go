@@ -0,0 +1,80 @@
+package bdatamatrix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestToXML tests ToXML with child elements and attributes.
+func TestToXML(t *testing.T) {
+	matrix, _ := NewWithData([][]string{{"1", "Tom & <Jerry>", "x"}}, "ID", "Full Name", "2nd")
+	matrix.SetNull(0, "2nd")
+
+	got := matrix.ToXML(XMLOptions{OmitDeclaration: true}).String()
+	expected := `<rows><row><ID>1</ID><Full_Name>Tom &amp; &lt;Jerry&gt;</Full_Name></row></rows>`
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	got = matrix.ToXML(XMLOptions{Root: "people", Row: "person", Attributes: true, Indent: true}).String()
+	expected = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		"<people>\n  <person ID=\"1\" Full_Name=\"Tom &amp; &lt;Jerry&gt;\"></person>\n</people>\n"
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	for name, expected := range map[string]string{"": "_", "2nd": "_2nd", "a:b c": "a_b_c", "é-1.x": "é-1.x"} {
+		if got := xmlName(name); got != expected {
+			t.Fatalf("expected %q to be sanitized to %q, got %q", name, expected, got)
+		}
+	}
+}
+
+// TestFromXML tests FromXML with child elements, attributes and row paths.
+func TestFromXML(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<export>
+  <rows>
+    <row id="1"><name>Tom &amp; Jerry</name><age>30</age></row>
+    <row id="2"><name><![CDATA[<Bob>]]></name><email/></row>
+  </rows>
+  <meta><row id="ignored"/></meta>
+</export>`
+
+	matrix, err := FromXML(strings.NewReader(input), "rows/row")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertHeader(t, matrix, "id", "name", "age", "email")
+	assertColumn(t, matrix, "name", "Tom & Jerry", "<Bob>")
+	if null, _ := matrix.IsNull(1, "age"); !null {
+		t.Fatalf("expected a missing element to be a null cell")
+	}
+	if null, _ := matrix.IsNull(1, "email"); null {
+		t.Fatalf("expected an empty element not to be a null cell")
+	}
+
+	all, _ := FromXML(strings.NewReader(input), "row")
+	assertColumn(t, all, "id", "1", "2", "ignored")
+	if _, err = FromXML(strings.NewReader(input), "/rows/row"); !errors.Is(err, ErrNoRowsFound) {
+		t.Fatalf("expected no rows found error, got %v", err)
+	}
+	if _, err = FromXML(strings.NewReader("<rows><row>"), "row"); !errors.Is(err, ErrMalformedInput) {
+		t.Fatalf("expected malformed input error, got %v", err)
+	}
+	for _, input := range []string{
+		`<rows><row id="1"><id>2</id></row></rows>`,
+		`<rows><row><name>a</name><name>b</name></row></rows>`,
+	} {
+		if _, err = FromXML(strings.NewReader(input), "row"); !errors.Is(err, ErrMalformedInput) {
+			t.Fatalf("%s: expected malformed input error, got %v", input, err)
+		}
+	}
+
+	roundTrip, err := FromXML(matrix.ToXML(XMLOptions{Attributes: true}), "/rows/row")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertColumn(t, roundTrip, "name", "Tom & Jerry", "<Bob>")
+}